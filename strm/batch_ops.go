@@ -0,0 +1,131 @@
+package strm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrBatchLength Returned by MapBatch and PMapBatch when the mapper doesn't return one value per element of a batch
+var ErrBatchLength = errors.New("strm: batch result length mismatch")
+
+// ErrBatchSize Returned by the batch mapping operations when the given batch size isn't positive
+var ErrBatchSize = errors.New("strm: batch size must be positive")
+
+// MapBatch Returns a new Stream containing the results of applying the given function to the consecutive batches of
+// up to [size] elements of the given Stream, split as Chunked does, e.g. for calling bulk APIs.
+// The function must return one value per element of its batch, in the same order, otherwise the ErrBatchLength
+// error is returned. The [size] must be positive, otherwise the ErrBatchSize error is returned.
+// The batches passed to the function are never backed by the slice given to From, which is preserved.
+func MapBatch[IN any, OUT any](s *Stream[IN], size int, f func(batch []IN) []OUT) (*Stream[OUT], error) {
+	defer s.exit(s.enter("MapBatch", true))
+	return mapBatches(s, size, false, orderedBatchMapper(f))
+}
+
+// PMapBatch Returns a new Stream containing the results of applying the given function to the batches of the given
+// Stream in parallel, see MapBatch. A new goroutine is launched per each batch.
+func PMapBatch[IN any, OUT any](s *Stream[IN], size int, f func(batch []IN) []OUT) (*Stream[OUT], error) {
+	defer s.exit(s.enter("PMapBatch", true))
+	return mapBatches(s, size, true, orderedBatchMapper(f))
+}
+
+// MapBatchByKey Returns a new Stream containing the results of applying the given function to the consecutive
+// batches of up to [size] elements of the given Stream, split as Chunked does, e.g. for calling bulk lookup APIs.
+// The function returns the values by the key produced by the given [keySelector] for their elements, which are
+// merged back in the order of the elements: the elements whose key is missing from the result of their batch are
+// dropped. The [size] must be positive, otherwise the ErrBatchSize error is returned.
+func MapBatchByKey[IN any, K comparable, OUT any](
+	s *Stream[IN], size int, keySelector func(IN) K, f func(batch []IN) map[K]OUT) (*Stream[OUT], error) {
+	defer s.exit(s.enter("MapBatchByKey", true))
+	return mapBatches(s, size, false, keyedBatchMapper(keySelector, f))
+}
+
+// PMapBatchByKey Returns a new Stream containing the results of applying the given function to the batches of the
+// given Stream in parallel, see MapBatchByKey. A new goroutine is launched per each batch.
+func PMapBatchByKey[IN any, K comparable, OUT any](
+	s *Stream[IN], size int, keySelector func(IN) K, f func(batch []IN) map[K]OUT) (*Stream[OUT], error) {
+	defer s.exit(s.enter("PMapBatchByKey", true))
+	return mapBatches(s, size, true, keyedBatchMapper(keySelector, f))
+}
+
+/*
+ * Internal Ops
+ */
+
+// maps a batch to the values of its elements, or fails
+type batchMapper[IN any, OUT any] func(batch []IN) ([]OUT, error)
+
+// returns a batchMapper validating that the given [f] returns one value per element
+func orderedBatchMapper[IN any, OUT any](f func([]IN) []OUT) batchMapper[IN, OUT] {
+	return func(batch []IN) ([]OUT, error) {
+		values := f(batch)
+		if len(values) != len(batch) {
+			return nil, fmt.Errorf("%w: %d values for %d elements", ErrBatchLength, len(values), len(batch))
+		}
+		return values, nil
+	}
+}
+
+// returns a batchMapper merging the values returned by the given [f] by the keys of the elements
+func keyedBatchMapper[IN any, K comparable, OUT any](
+	keySelector func(IN) K, f func([]IN) map[K]OUT) batchMapper[IN, OUT] {
+	return func(batch []IN) ([]OUT, error) {
+		byKey := f(batch)
+		values := make([]OUT, 0, len(batch))
+		for _, elem := range batch {
+			if value, ok := byKey[keySelector(elem)]; ok {
+				values = append(values, value)
+			}
+		}
+		return values, nil
+	}
+}
+
+// applies the given batchMapper to the batches of up to [size] elements of the given Stream, sequentially or with
+// a goroutine per batch, and flattens their values in order. Returns the error of the first failed batch.
+func mapBatches[IN any, OUT any](s *Stream[IN], size int, parallel bool, f batchMapper[IN, OUT]) (*Stream[OUT], error) {
+	if size <= 0 {
+		return nil, s.failed(fmt.Errorf("%w: %d", ErrBatchSize, size))
+	}
+	s.filteredSlice()
+	// the batches may be written to by the mapper: they mustn't alias the slice of the caller
+	s.own()
+	slice := s.slice
+	batches := make([][]IN, 0, (len(slice)+size-1)/size)
+	for lo := 0; lo < len(slice); lo += size {
+		hi := min(lo+size, len(slice))
+		batches = append(batches, slice[lo:hi:hi])
+	}
+
+	results := make([][]OUT, len(batches))
+	errs := make([]error, len(batches))
+	if parallel {
+		var wg sync.WaitGroup
+		wg.Add(len(batches))
+		for i, batch := range batches {
+			go func() {
+				defer wg.Done()
+				results[i], errs[i] = f(batch)
+			}()
+		}
+		wg.Wait()
+		if stage := s.currentStage(); stage != nil {
+			stage.Goroutines = len(batches)
+		}
+	} else {
+		for i, batch := range batches {
+			if results[i], errs[i] = f(batch); errs[i] != nil {
+				break
+			}
+		}
+	}
+
+	newSlice := make([]OUT, 0, len(slice))
+	for i, values := range results {
+		if errs[i] != nil {
+			return nil, s.failed(fmt.Errorf("strm: mapping batch at index %d: %w", i, errs[i]))
+		}
+		newSlice = append(newSlice, values...)
+	}
+	return derive(s, newSlice), nil
+}
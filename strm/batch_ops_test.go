@@ -0,0 +1,158 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMapBatch(t *testing.T) {
+	// prepare
+	var sizes []int
+	s := Of(1, 2, 3, 4, 5, 6, 7).Filter(func(n int) bool { return n != 4 })
+
+	// call
+	result, err := MapBatch(s, 4, func(batch []int) []string {
+		sizes = append(sizes, len(batch))
+		values := make([]string, len(batch))
+		for i, n := range batch {
+			values[i] = strconv.Itoa(n * 10)
+		}
+		return values
+	})
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10", "20", "30", "50", "60", "70"}, result.ToSlice(), "wrong mapping")
+	assert.Equal(t, []int{4, 2}, sizes, "wrong batch sizes")
+}
+
+func TestMapBatchLengthMismatch(t *testing.T) {
+	// prepare
+	calls := 0
+
+	// call
+	result, err := MapBatch(Of(1, 2, 3, 4, 5), 2, func(batch []int) []int {
+		calls++
+		return batch[:1]
+	})
+
+	// assert
+	assert.ErrorIs(t, err, ErrBatchLength, "wrong error")
+	assert.EqualError(t, err, "strm: mapping batch at index 0: strm: batch result length mismatch: 1 values for 2 elements")
+	assert.Nil(t, result, "no Stream should be returned")
+	assert.Equal(t, 1, calls, "mapping should stop at the failed batch")
+}
+
+func TestPMapBatch(t *testing.T) {
+	// prepare
+	slice := make([]int, 100)
+	for i := range slice {
+		slice[i] = i
+	}
+	var calls atomic.Int32
+	s := From(slice)
+
+	// call
+	result, err := PMapBatch(s, 8, func(batch []int) []int {
+		calls.Add(1)
+		values := make([]int, len(batch))
+		for i, n := range batch {
+			values[i] = n * n
+		}
+		return values
+	})
+
+	// assert
+	require.NoError(t, err)
+	for i, value := range result.ToSlice() {
+		assert.Equal(t, i*i, value, "the order of the elements should be preserved")
+	}
+	assert.Equal(t, int32(13), calls.Load(), "wrong number of batches")
+	assert.Equal(t, 13, result.Explain().Stages[0].Goroutines, "wrong number of goroutines")
+}
+
+func TestPMapBatchLengthMismatch(t *testing.T) {
+	// call
+	_, err := PMapBatch(Of(1, 2, 3, 4, 5), 2, func(batch []int) []int {
+		if batch[0] == 5 {
+			return nil
+		}
+		return batch
+	})
+
+	// assert
+	assert.ErrorIs(t, err, ErrBatchLength, "wrong error")
+	assert.ErrorContains(t, err, "batch at index 2", "the failed batch should be reported")
+}
+
+func TestMapBatchByKey(t *testing.T) {
+	// prepare
+	type Person struct {
+		name string
+		age  int
+	}
+	people := []Person{{"Peter", 18}, {"John", 30}, {"Bruce", 18}, {"Mary", 45}}
+	lookup := func(batch []Person) map[string]string {
+		emails := map[string]string{}
+		for _, p := range batch {
+			if p.name != "Bruce" { // unknown
+				emails[p.name] = p.name + "@example.com"
+			}
+		}
+		return emails
+	}
+	byName := func(p Person) string { return p.name }
+
+	// call
+	emails, err := MapBatchByKey(From(people), 3, byName, lookup)
+	parallelEmails, err2 := PMapBatchByKey(From(people), 1, byName, lookup)
+
+	// assert
+	require.NoError(t, err)
+	require.NoError(t, err2)
+	expected := []string{"Peter@example.com", "John@example.com", "Mary@example.com"}
+	assert.Equal(t, expected, emails.ToSlice(), "wrong merge")
+	assert.Equal(t, expected, parallelEmails.ToSlice(), "wrong merge")
+}
+
+func TestMapBatchInvalidSize(t *testing.T) {
+	// call
+	result, err := MapBatch(Of(1), 0, func(batch []int) []int { return batch })
+	_, err2 := PMapBatchByKey(Of(1), -1, func(i int) int { return i }, func(batch []int) map[int]int { return nil })
+
+	// assert
+	assert.Nil(t, result, "no Stream expected")
+	assert.ErrorIs(t, err, ErrBatchSize, "wrong error")
+	assert.ErrorIs(t, err2, ErrBatchSize, "wrong error")
+}
+
+func TestMapBatchPreservesSourceSlice(t *testing.T) {
+	// prepare
+	source := []int{1, 2, 3, 4}
+	double := func(batch []int) []int {
+		for i := range batch {
+			batch[i] *= 2
+		}
+		return batch
+	}
+
+	// call
+	result, err := PMapBatch(From(source), 2, double)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 4, 6, 8}, result.ToSlice(), "wrong mapping")
+	assert.Equal(t, []int{1, 2, 3, 4}, source, "source slice should be preserved")
+}
+
+func TestMapBatchEmpty(t *testing.T) {
+	// call
+	result, err := PMapBatch(Of[int](), 3, func(batch []int) []int { return batch })
+
+	// assert
+	require.NoError(t, err)
+	assert.Empty(t, result.ToSlice(), "wrong mapping")
+}
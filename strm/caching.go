@@ -0,0 +1,263 @@
+package strm
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache A concurrency-safe cache of mapped values, keyed by the hash of the mapped elements, see MapCached.
+// The keys don't identify the mapper: a Cache is meant to be shared only by the operations calling the same mapper,
+// as sharing it with another one returns the values cached by the first.
+type Cache[V any] interface {
+	// Get Returns the value cached for the given [key], and whether it was found
+	Get(key any) (V, bool)
+	// Put Caches the given [value] for the given [key]
+	Put(key any, value V)
+}
+
+// MapCached Returns a new Stream containing the results of applying the given function to each element in the
+// given Stream, calling it once per distinct element: elements equal to a previous one, as compared by Distinct,
+// get the value held by the given [cache], an unbounded map cache when nil.
+// The [cache] may be shared by several Streams calling the same function [f], for reusing the values across them.
+func MapCached[IN any, OUT any](s *Stream[IN], f mapper[IN, OUT], cache Cache[OUT]) *Stream[OUT] {
+	defer s.exit(s.enter("MapCached", true))
+	cache = orMapCache(cache)
+	newSlice := make([]OUT, len(s.filteredSlice()))
+
+	for i, elem := range s.slice {
+		key := s.calculateHash(i)
+		value, ok := cache.Get(key)
+		if !ok {
+			value = f(elem)
+			cache.Put(key, value)
+		}
+		newSlice[i] = value
+	}
+	return derive(s, newSlice)
+}
+
+// PMapCached Returns a new Stream containing the results of applying the given function to each element in the
+// given Stream in parallel, see PMap, calling it once per distinct element as MapCached does.
+// The concurrent calls for equal elements are deduplicated: a single one runs while the others wait for its value.
+func PMapCached[IN any, OUT any](s *Stream[IN], f mapper[IN, OUT], cache Cache[OUT], batching ...bool) *Stream[OUT] {
+	defer s.exit(s.enter("PMapCached", true))
+	flights := &flightGroup[OUT]{cache: orMapCache(cache), calls: make(map[any]*flight[OUT])}
+	mapIdx := func(idx int) OUT {
+		return flights.do(s.calculateHash(idx), func() OUT { return f(s.slice[idx]) })
+	}
+	if len(batching) == 0 {
+		return parallelLinearMap(s, mapIdx)
+	}
+	return parallelBatchingMap(s, mapIdx)
+}
+
+/*
+ * Caches
+ */
+
+// MapCache An unbounded Cache backed by a map
+type MapCache[V any] struct {
+	mu      sync.RWMutex
+	entries map[any]V
+}
+
+// NewMapCache Creates a new empty MapCache
+func NewMapCache[V any]() *MapCache[V] {
+	return &MapCache[V]{entries: make(map[any]V)}
+}
+
+// Get Returns the value cached for the given [key], and whether it was found
+func (c *MapCache[V]) Get(key any) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.entries[key]
+	return value, ok
+}
+
+// Put Caches the given [value] for the given [key]
+func (c *MapCache[V]) Put(key any, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = value
+}
+
+// Len Returns the number of cached values
+func (c *MapCache[V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// LRUCache A Cache holding up to a maximum number of values, evicting the least recently used one when full
+type LRUCache[V any] struct {
+	mu      sync.Mutex
+	maxSize int
+	order   *list.List // front: most recently used
+	entries map[any]*list.Element
+}
+
+type lruEntry[V any] struct {
+	key   any
+	value V
+}
+
+// NewLRUCache Creates a new empty LRUCache holding up to [maxSize] values, at least one
+func NewLRUCache[V any](maxSize int) *LRUCache[V] {
+	return &LRUCache[V]{maxSize: max(maxSize, 1), order: list.New(), entries: make(map[any]*list.Element)}
+}
+
+// Get Returns the value cached for the given [key], and whether it was found
+func (c *LRUCache[V]) Get(key any) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, found := c.entries[key]; found {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*lruEntry[V]).value, true
+	}
+	return value, false
+}
+
+// Put Caches the given [value] for the given [key], evicting the least recently used value when full
+func (c *LRUCache[V]) Put(key any, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, found := c.entries[key]; found {
+		elem.Value.(*lruEntry[V]).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+	if c.order.Len() >= c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key, value})
+}
+
+// Len Returns the number of cached values
+func (c *LRUCache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// TTLCache A Cache expiring its values once they're older than a given time-to-live
+type TTLCache[V any] struct {
+	mu      sync.Mutex
+	clock   Clock
+	ttl     time.Duration
+	entries map[any]ttlEntry[V]
+	sweepAt int // the number of entries triggering the removal of the expired ones
+}
+
+type ttlEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// NewTTLCache Creates a new empty TTLCache expiring its values after the given [ttl], measured by the given
+// [clock], SystemClock when nil
+func NewTTLCache[V any](ttl time.Duration, clock Clock) *TTLCache[V] {
+	return &TTLCache[V]{clock: orSystemClock(clock), ttl: ttl, entries: make(map[any]ttlEntry[V]), sweepAt: 64}
+}
+
+// Get Returns the value cached for the given [key], and whether it was found and not expired
+func (c *TTLCache[V]) Get(key any) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, found := c.entries[key]
+	if !found {
+		return value, false
+	}
+	if !c.clock.Now().Before(entry.expires) {
+		delete(c.entries, key)
+		return value, false
+	}
+	return entry.value, true
+}
+
+// Put Caches the given [value] for the given [key], until the time-to-live of this TTLCache elapses
+func (c *TTLCache[V]) Put(key any, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	// amortized removal of the expired values never read again
+	if len(c.entries) >= c.sweepAt {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.sweepAt = max(2*len(c.entries), 64)
+	}
+	c.entries[key] = ttlEntry[V]{value, now.Add(c.ttl)}
+}
+
+// Len Returns the number of cached values, including the expired ones not removed yet
+func (c *TTLCache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+/*
+ * Internal Ops
+ */
+
+// returns the given cache, or a new MapCache when nil
+func orMapCache[V any](cache Cache[V]) Cache[V] {
+	if cache == nil {
+		return NewMapCache[V]()
+	}
+	return cache
+}
+
+// deduplicates the concurrent calls for the same key, backed by a Cache
+type flightGroup[V any] struct {
+	mu    sync.Mutex
+	cache Cache[V]
+	calls map[any]*flight[V] // the calls in flight
+}
+
+type flight[V any] struct {
+	done  chan struct{}
+	value V
+	// the value the call panicked with, raised again by the calls waiting for it
+	panicked any
+}
+
+// returns the value cached for the given [key], or the one of the call in flight for it, or else calls [f] and
+// caches its value. A panic of [f] is raised again by the calls waiting for it, nothing being cached.
+func (g *flightGroup[V]) do(key any, f func() V) V {
+	g.mu.Lock()
+	if value, ok := g.cache.Get(key); ok {
+		g.mu.Unlock()
+		return value
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-call.done
+		if call.panicked != nil {
+			panic(call.panicked)
+		}
+		return call.value
+	}
+	call := &flight[V]{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		call.panicked = recover()
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+		if call.panicked != nil {
+			panic(call.panicked)
+		}
+	}()
+	call.value = f()
+	g.cache.Put(key, call.value)
+	return call.value
+}
@@ -0,0 +1,148 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type lookup struct {
+	Country string
+	Tags    []string // not comparable: hashed
+}
+
+func TestMapCached(t *testing.T) {
+	// prepare
+	calls := map[string]int{}
+	lookups := []lookup{{"pt", []string{"a"}}, {"es", nil}, {"pt", []string{"a"}}, {"pt", []string{"b"}}, {"es", nil}}
+
+	// call
+	result := MapCached(From(lookups), func(l lookup) string {
+		calls[l.Country+strings.Join(l.Tags, ",")]++
+		return strings.ToUpper(l.Country)
+	}, nil).ToSlice()
+
+	// assert
+	assert.Equal(t, []string{"PT", "ES", "PT", "PT", "ES"}, result, "wrong mapping")
+	assert.Equal(t, map[string]int{"pta": 1, "es": 1, "ptb": 1}, calls, "each distinct element should be mapped once")
+}
+
+func TestMapCachedSharedCache(t *testing.T) {
+	// prepare
+	cache := NewMapCache[int]()
+	calls := 0
+	square := func(n int) int {
+		calls++
+		return n * n
+	}
+
+	// call
+	first := MapCached(Of(1, 2, 3), square, cache).ToSlice()
+	second := MapCached(Of(3, 2, 4).Filter(func(n int) bool { return n > 2 }), square, cache).ToSlice()
+
+	// assert
+	assert.Equal(t, []int{1, 4, 9}, first, "wrong mapping")
+	assert.Equal(t, []int{9, 16}, second, "wrong mapping")
+	assert.Equal(t, 4, calls, "cached values should be reused across Streams")
+	assert.Equal(t, 4, cache.Len(), "wrong cache size")
+}
+
+func TestPMapCached(t *testing.T) {
+	for _, batching := range [][]bool{nil, {true}} {
+		// prepare
+		var calls atomic.Int32
+		slice := make([]int, 200)
+		for i := range slice {
+			slice[i] = i % 5
+		}
+
+		// call
+		result := PMapCached(From(slice), func(n int) int {
+			calls.Add(1)
+			time.Sleep(time.Millisecond) // keeps the call in flight
+			return n * 10
+		}, NewLRUCache[int](10), batching...).ToSlice()
+
+		// assert
+		for i, value := range result {
+			assert.Equal(t, slice[i]*10, value, "wrong mapping")
+		}
+		assert.Equal(t, int32(5), calls.Load(), "concurrent calls for equal elements should be shared")
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	// prepare
+	cache := NewLRUCache[string](2)
+	cache.Put(1, "one")
+	cache.Put(2, "two")
+
+	// call
+	_, _ = cache.Get(1) // 2 becomes the least recently used
+	cache.Put(3, "three")
+
+	// assert
+	_, found := cache.Get(2)
+	assert.False(t, found, "the least recently used value should be evicted")
+	one, _ := cache.Get(1)
+	three, _ := cache.Get(3)
+	assert.Equal(t, "one", one, "wrong cached value")
+	assert.Equal(t, "three", three, "wrong cached value")
+	assert.Equal(t, 2, cache.Len(), "wrong cache size")
+}
+
+func TestTTLCache(t *testing.T) {
+	// prepare
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := NewTTLCache[int](time.Minute, clock)
+	cache.Put("a", 1)
+	clock.Advance(30 * time.Second)
+	cache.Put("b", 2)
+
+	// call
+	clock.Advance(30 * time.Second)
+
+	// assert
+	_, found := cache.Get("a")
+	assert.False(t, found, "the value should be expired")
+	b, found := cache.Get("b")
+	assert.True(t, found, "the value should not be expired")
+	assert.Equal(t, 2, b, "wrong cached value")
+	assert.Equal(t, 1, cache.Len(), "the expired value should be removed")
+}
+
+func TestTTLCacheSweep(t *testing.T) {
+	// prepare
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := NewTTLCache[int](time.Minute, clock)
+	for i := range 64 {
+		cache.Put(i, i)
+	}
+	clock.Advance(time.Minute)
+
+	// call
+	cache.Put("fresh", 1)
+
+	// assert
+	assert.Equal(t, 1, cache.Len(), "the expired values should be swept")
+}
+
+func TestFlightGroupPanic(t *testing.T) {
+	// prepare
+	flights := &flightGroup[int]{cache: NewMapCache[int](), calls: make(map[any]*flight[int])}
+	waited := &flight[int]{done: make(chan struct{}), panicked: "lookup failed"}
+	close(waited.done)
+	flights.calls["waited"] = waited
+
+	// call & assert
+	assert.PanicsWithValue(t, "lookup failed", func() { flights.do("key", func() int { panic("lookup failed") }) },
+		"the panic of the call should be raised again")
+	assert.NotContains(t, flights.calls, "key", "the panicked call should be removed")
+	_, cached := flights.cache.Get("key")
+	assert.False(t, cached, "nothing should be cached")
+	assert.PanicsWithValue(t, "lookup failed", func() { flights.do("waited", func() int { return 1 }) },
+		"the calls waiting for a panicked call should panic too")
+	assert.Equal(t, 2, flights.do("key", func() int { return 2 }), "a new call should be made after a panic")
+}
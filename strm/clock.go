@@ -0,0 +1,105 @@
+package strm
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock The source of time of the time-aware operations, injectable for testing them deterministically
+type Clock interface {
+	Now() time.Time
+	// After Returns a channel receiving the current time once the given [d] has elapsed
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock The Clock of the time package, the default one
+var SystemClock Clock = systemClock{}
+
+// ManualClock A Clock only moving forward when advanced, e.g. by tests
+type ManualClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []manualTimer
+}
+
+// NewManualClock Creates a new ManualClock set at the given time [now]
+func NewManualClock(now time.Time) *ManualClock {
+	c := &ManualClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now Returns the current time of this ManualClock
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After Returns a channel receiving the time of this ManualClock once it's advanced by the given [d]
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, manualTimer{c.now.Add(d), ch})
+	c.cond.Broadcast()
+	return ch
+}
+
+// Advance Moves this ManualClock forward by the given [d], firing the timers due by then in order
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].deadline.Before(c.timers[j].deadline) })
+	fired := 0
+	for fired < len(c.timers) && !c.timers[fired].deadline.After(c.now) {
+		c.timers[fired].ch <- c.now
+		fired++
+	}
+	c.timers = c.timers[fired:]
+}
+
+// BlockUntil Blocks until at least [n] timers are waiting for this ManualClock to be advanced,
+// e.g. for a test to wait for an operation running on another goroutine
+func (c *ManualClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+/*
+ * Internal Ops
+ */
+
+type systemClock struct{}
+
+// returns the given Clock, or SystemClock if nil
+func orSystemClock(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// a pending timer of a ManualClock
+type manualTimer struct {
+	deadline time.Time
+	ch       chan time.Time
+}
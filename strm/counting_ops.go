@@ -0,0 +1,144 @@
+package strm
+
+import (
+	"math"
+	"sort"
+)
+
+// Bucket A histogram bucket counting the elements within [Lower] (inclusive) and [Upper] (exclusive).
+// The last bucket of a histogram also includes its [Upper] bound.
+type Bucket struct {
+	Lower float64
+	Upper float64
+	Count int
+}
+
+// Frequency A value and its number of occurrences, as produced by MostCommon
+type Frequency[T any] struct {
+	Value T
+	Count int
+}
+
+// Histogram Returns the ordered buckets delimited by the given strictly increasing [boundaries], counting the
+// elements of the given Stream within each one. n boundaries produce n-1 buckets. Elements out of the boundaries,
+// and NaNs, aren't counted. Returns nil if there are fewer than 2 boundaries or they're not strictly increasing.
+func Histogram[N Number](s *Stream[N], boundaries []float64) []Bucket {
+	defer s.exit(s.enter("Histogram", true))
+	if len(boundaries) < 2 {
+		return nil
+	}
+	for i := 1; i < len(boundaries); i++ {
+		if !(boundaries[i-1] < boundaries[i]) { // NaN included
+			return nil
+		}
+	}
+	buckets := make([]Bucket, len(boundaries)-1)
+	for i := range buckets {
+		buckets[i] = Bucket{Lower: boundaries[i], Upper: boundaries[i+1]}
+	}
+	last := len(buckets) - 1
+
+	for _, elem := range s.filteredSlice() {
+		v := float64(elem)
+		if !(v >= boundaries[0] && v <= boundaries[last+1]) { // NaN included
+			continue
+		}
+		// the first boundary above v closes its bucket
+		idx := sort.SearchFloat64s(boundaries, v)
+		if idx < len(boundaries) && boundaries[idx] == v {
+			idx++
+		}
+		if idx-1 > last {
+			idx = last + 1
+		}
+		buckets[idx-1].Count++
+	}
+	return buckets
+}
+
+// EqualWidthHistogram Returns [nBuckets] ordered buckets of equal width, spanning from the smallest to the largest
+// element of the given Stream and counting the elements within each one. NaNs and infinite elements aren't counted.
+// Returns nil if the Stream has no finite elements, and a single bucket if all of them are equal.
+func EqualWidthHistogram[N Number](s *Stream[N], nBuckets int) []Bucket {
+	defer s.exit(s.enter("EqualWidthHistogram", true))
+	if nBuckets < 1 {
+		return nil
+	}
+	count, min, max := 0, math.Inf(1), math.Inf(-1)
+	for _, elem := range s.filteredSlice() {
+		if v := float64(elem); isFinite(v) {
+			count, min, max = count+1, math.Min(min, v), math.Max(max, v)
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	if min == max {
+		return []Bucket{{Lower: min, Upper: max, Count: count}}
+	}
+
+	width := (max - min) / float64(nBuckets)
+	buckets := make([]Bucket, nBuckets)
+	for i := range buckets {
+		buckets[i] = Bucket{Lower: min + float64(i)*width, Upper: min + float64(i+1)*width}
+	}
+	// avoids float rounding errors on the upper bound
+	buckets[nBuckets-1].Upper = max
+
+	for _, elem := range s.slice {
+		if !isFinite(elem) {
+			continue
+		}
+		idx := int((float64(elem) - min) / width)
+		if idx >= nBuckets {
+			idx = nBuckets - 1
+		}
+		buckets[idx].Count++
+	}
+	return buckets
+}
+
+// Frequencies Returns a map associating each distinct element of the given Stream with its number of occurrences
+func Frequencies[T comparable](s *Stream[T]) map[T]int {
+	defer s.exit(s.enter("Frequencies", true))
+	frequencies := make(map[T]int)
+	for _, elem := range s.filteredSlice() {
+		frequencies[elem]++
+	}
+	return frequencies
+}
+
+// FrequenciesBy Returns a map associating each key produced by the given [keySelector] with the number of
+// elements of the given Stream producing it, without allocating the groups' elements like GroupBy does
+func FrequenciesBy[T any, K comparable](s *Stream[T], keySelector func(T) K) map[K]int {
+	defer s.exit(s.enter("FrequenciesBy", true))
+	frequencies := make(map[K]int)
+	for _, elem := range s.filteredSlice() {
+		frequencies[keySelector(elem)]++
+	}
+	return frequencies
+}
+
+// MostCommon Returns the [n] most frequent elements of the given Stream with their number of occurrences,
+// ordered from the most frequent. Ties are ordered by first occurrence in the Stream. A negative [n] returns none.
+func MostCommon[T comparable](s *Stream[T], n int) []Frequency[T] {
+	defer s.exit(s.enter("MostCommon", true))
+	n = max(n, 0)
+	indexes := make(map[T]int)
+	var frequencies []Frequency[T]
+
+	for _, elem := range s.filteredSlice() {
+		if idx, ok := indexes[elem]; ok {
+			frequencies[idx].Count++
+			continue
+		}
+		indexes[elem] = len(frequencies)
+		frequencies = append(frequencies, Frequency[T]{Value: elem, Count: 1})
+	}
+	sort.SliceStable(frequencies, func(i, j int) bool { return frequencies[i].Count > frequencies[j].Count })
+
+	if n < len(frequencies) {
+		return frequencies[:n:n]
+	}
+	return frequencies
+}
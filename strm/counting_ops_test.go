@@ -0,0 +1,78 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	// call
+	buckets := Histogram(Of(1, 5, 10, 15, 20, 99, 100, 101, -1), []float64{0, 10, 20, 100})
+	none := Histogram(Of(1, 2), []float64{0})
+
+	// assert
+	assert.Equal(t, []Bucket{{0, 10, 2}, {10, 20, 2}, {20, 100, 3}}, buckets, "wrong buckets")
+	assert.Nil(t, none, "wrong buckets")
+}
+
+func TestEqualWidthHistogram(t *testing.T) {
+	// call
+	buckets := EqualWidthHistogram(Of(0.0, 1, 2.5, 2.6, 4.9, 10), 4)
+	sameValues := EqualWidthHistogram(Of(3, 3, 3), 4)
+	empty := EqualWidthHistogram(Of[int](), 4)
+
+	// assert
+	assert.Equal(t, []Bucket{{0, 2.5, 2}, {2.5, 5, 3}, {5, 7.5, 0}, {7.5, 10, 1}}, buckets, "wrong buckets")
+	assert.Equal(t, []Bucket{{3, 3, 3}}, sameValues, "wrong buckets")
+	assert.Nil(t, empty, "wrong buckets")
+}
+
+func TestFrequencies(t *testing.T) {
+	// call
+	frequencies := Frequencies(Of("a", "b", "a", "c", "a"))
+	byAge := FrequenciesBy(
+		Of(Person{"Tim", 30}, Person{"Bil", 40}, Person{"John", 30}),
+		func(p Person) int { return p.age },
+	)
+
+	// assert
+	assert.Equal(t, map[string]int{"a": 3, "b": 1, "c": 1}, frequencies, "wrong frequencies")
+	assert.Equal(t, map[int]int{30: 2, 40: 1}, byAge, "wrong frequencies")
+}
+
+func TestMostCommon(t *testing.T) {
+	// call
+	top2 := MostCommon(Of("c", "b", "a", "b", "a", "a"), 2)
+	all := MostCommon(Of(1, 2, 2), 5)
+
+	// assert
+	assert.Equal(t, []Frequency[string]{{"a", 3}, {"b", 2}}, top2, "wrong most common")
+	assert.Equal(t, []Frequency[int]{{2, 2}, {1, 1}}, all, "wrong most common")
+}
+
+func TestHistogramInvalidInputs(t *testing.T) {
+	// call
+	unordered := Histogram(Of(1, 2), []float64{0, 10, 5})
+	repeated := Histogram(Of(1, 2), []float64{0, 10, 10})
+	nanBoundary := Histogram(Of(1, 2), []float64{0, math.NaN()})
+	nanElems := Histogram(Of(1, math.NaN(), 2), []float64{0, 10})
+	equalWidth := EqualWidthHistogram(Of(0, math.NaN(), 10, math.Inf(1), math.Inf(-1), 5), 2)
+	nonFinite := EqualWidthHistogram(Of(math.NaN(), math.Inf(1)), 2)
+
+	// assert
+	assert.Nil(t, unordered, "boundaries should be increasing")
+	assert.Nil(t, repeated, "boundaries should be strictly increasing")
+	assert.Nil(t, nanBoundary, "boundaries should be numbers")
+	assert.Equal(t, []Bucket{{0, 10, 2}}, nanElems, "NaNs shouldn't be counted")
+	assert.Equal(t, []Bucket{{0, 5, 1}, {5, 10, 2}}, equalWidth, "non-finite elements shouldn't be counted")
+	assert.Nil(t, nonFinite, "wrong buckets")
+}
+
+func TestMostCommonNegative(t *testing.T) {
+	// call
+	none := MostCommon(Of(1, 2, 2), -1)
+
+	// assert
+	assert.Empty(t, none, "wrong frequencies")
+}
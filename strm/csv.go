@@ -0,0 +1,319 @@
+package strm
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVOptions The options of FromCSV, its zero value reading comma-separated rows after a header row
+// and stopping at the first malformed row
+type CSVOptions struct {
+	// Comma the field delimiter, ',' when zero
+	Comma rune
+	// Comment the character starting comment lines, none when zero
+	Comment rune
+	// NoHeader true when the first row isn't a header: the columns are then mapped to the fields in declaration order
+	NoHeader bool
+	// OnError the ErrorPolicy for malformed rows, StopOnError when nil
+	OnError ErrorPolicy
+}
+
+// FromCSV Creates a new LazyStream of the rows read from the given [reader], decoded into the struct type T.
+// Columns are mapped to the exported fields of T by the names in their `csv:"name"` tags, or their field names,
+// and fields tagged `csv:"-"` are ignored. Columns missing from the header leave their fields to the zero value.
+// Fields may be strings, bools, numbers, time.Duration, time.Time (RFC 3339), encoding.TextUnmarshaler
+// implementations, or pointers to them, nil for empty values.
+// Rows which can't be decoded are reported as RecordError to the ErrorPolicy of the given [opts].
+func FromCSV[T any](reader io.Reader, opts CSVOptions) *LazyStream[T] {
+	s := &LazyStream[T]{src: &lazySource{}}
+	s.seq = func(yield func(T) bool) {
+		fields, err := csvFieldsOf[T]()
+		if err != nil {
+			s.src.fail(err)
+			return
+		}
+		r := csv.NewReader(reader)
+		r.ReuseRecord = true
+		if opts.Comma != 0 {
+			r.Comma = opts.Comma
+		}
+		r.Comment = opts.Comment
+
+		columns := make([]*csvField, len(fields))
+		if opts.NoHeader {
+			for i := range fields {
+				columns[i] = &fields[i]
+			}
+		} else {
+			header, err := r.Read()
+			if err != nil {
+				if err != io.EOF {
+					s.src.fail(fmt.Errorf("strm: reading CSV header: %w", err))
+				}
+				return
+			}
+			columns = csvColumns(header, fields)
+		}
+
+		for record := 1; ; record++ {
+			row, err := r.Read()
+			if err == io.EOF {
+				return
+			}
+			var parseErr *csv.ParseError
+			if err != nil && !errors.As(err, &parseErr) {
+				s.src.fail(err)
+				return
+			}
+
+			var elem T
+			if err == nil {
+				err = decodeCSVRow(reflect.ValueOf(&elem).Elem(), columns, row)
+			}
+			if err != nil {
+				recordErr, ok := err.(*RecordError)
+				if !ok {
+					recordErr = &RecordError{Err: err}
+				}
+				recordErr.Record = record
+				if opts.OnError.skip(recordErr) {
+					continue
+				}
+				s.src.fail(recordErr)
+				return
+			}
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+	return s
+}
+
+// ToCSV Writes the elements of this Stream of structs to the given [writer] as CSV rows, after a header row.
+// Fields are mapped to columns as by FromCSV, time.Time values being formatted as RFC 3339,
+// and encoding.TextMarshaler implementations by their MarshalText.
+func (s *Stream[T]) ToCSV(writer io.Writer) error {
+	defer s.exit(s.enter("ToCSV", true))
+	return writeCSV(writer, s.values())
+}
+
+// ToCSV Writes the elements of this LazyStream of structs to the given [writer] as CSV rows, see Stream.ToCSV.
+// The first error of the source is returned if writing didn't fail.
+func (s *LazyStream[T]) ToCSV(writer io.Writer) error {
+	s.use("ToCSV", true)
+	defer s.src.finish()
+	if err := writeCSV(writer, s.seq); err != nil {
+		return err
+	}
+	return s.src.error()
+}
+
+/*
+ * Internal Ops
+ */
+
+// an exported struct field mapped to a CSV column
+type csvField struct {
+	name  string
+	index []int
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// returns the CSV columns of the exported fields of the struct type T
+func csvFieldsOf[T any]() ([]csvField, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("strm: CSV elements must be structs, not %v", t)
+	}
+	var fields []csvField
+	for _, f := range reflect.VisibleFields(t) {
+		// fields promoted through embedded pointers are skipped, as they may be nil
+		if !f.IsExported() || f.Anonymous || len(f.Index) > 1 && viaPointer(t, f.Index) {
+			continue
+		}
+		name := f.Tag.Get("csv")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, csvField{name, f.Index})
+	}
+	return fields, nil
+}
+
+// returns true if the field at the given [index] of the struct type [t] is promoted through an embedded pointer
+func viaPointer(t reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		t = t.Field(i).Type
+		if t.Kind() == reflect.Pointer {
+			return true
+		}
+	}
+	return false
+}
+
+// maps the given header columns to the given fields, nil for unknown columns
+func csvColumns(header []string, fields []csvField) []*csvField {
+	columns := make([]*csvField, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF"))
+		for j := range fields {
+			if fields[j].name == name {
+				columns[i] = &fields[j]
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// decodes the given CSV row into the given struct value
+func decodeCSVRow(elem reflect.Value, columns []*csvField, row []string) error {
+	for i, text := range row {
+		if i >= len(columns) || columns[i] == nil {
+			continue
+		}
+		if err := decodeText(elem.FieldByIndex(columns[i].index), text); err != nil {
+			return &RecordError{Field: columns[i].name, Err: err}
+		}
+	}
+	return nil
+}
+
+// decodes the given text into the given settable value
+func decodeText(v reflect.Value, text string) error {
+	if v.Kind() == reflect.Pointer {
+		if text == "" {
+			v.SetZero()
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	// time.Time implements encoding.TextUnmarshaler, hence it's checked first
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(text)
+		v.SetInt(int64(d))
+		return err
+	case v.Type() == timeType:
+		t, err := time.Parse(time.RFC3339, text)
+		v.Set(reflect.ValueOf(t))
+		return err
+	case v.Addr().Type().Implements(textUnmarshalerType):
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %v", v.Type())
+	}
+	return nil
+}
+
+// encodes the given value as text
+func encodeText(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	// time.Time implements encoding.TextMarshaler, hence it's checked first
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339), nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			return time.Duration(v.Int()).String(), nil
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("strm: unsupported CSV field type %v", v.Type())
+}
+
+// writes the given elements as CSV rows after a header row
+func writeCSV[T any](writer io.Writer, elems iter.Seq[T]) error {
+	fields, err := csvFieldsOf[T]()
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(writer)
+	row := make([]string, len(fields))
+	for i, f := range fields {
+		row[i] = f.name
+	}
+	if err := w.Write(row); err != nil {
+		return err
+	}
+	for elem := range elems {
+		v := reflect.ValueOf(elem)
+		for i, f := range fields {
+			if row[i], err = encodeText(v.FieldByIndex(f.index)); err != nil {
+				return err
+			}
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
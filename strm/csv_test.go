@@ -0,0 +1,176 @@
+package strm
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type csvOrder struct {
+	ID       int           `csv:"id"`
+	Customer string        `csv:"customer"`
+	Amount   float64       `csv:"amount"`
+	Paid     bool          `csv:"paid"`
+	Placed   time.Time     `csv:"placed"`
+	Delay    time.Duration `csv:"delay"`
+	Discount *float64      `csv:"discount"`
+	Status   orderStatus   `csv:"status"`
+	internal string
+	Ignored  string `csv:"-"`
+}
+
+type orderStatus int
+
+func (s *orderStatus) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "open":
+		*s = 1
+	case "closed":
+		*s = 2
+	default:
+		return errors.New("unknown status " + strconv.Quote(string(text)))
+	}
+	return nil
+}
+
+func (s orderStatus) MarshalText() ([]byte, error) {
+	return []byte(map[orderStatus]string{1: "open", 2: "closed"}[s]), nil
+}
+
+const ordersCSV = `id,customer,amount,paid,placed,delay,discount,status
+1,alice,10.5,true,2024-01-02T10:00:00Z,1h30m,0.1,open
+2,bob,20,false,2024-01-03T11:00:00Z,0s,,closed
+`
+
+func TestFromCSV(t *testing.T) {
+	// prepare
+	reader := strings.NewReader(ordersCSV)
+
+	// call
+	orders, err := FromCSV[csvOrder](reader, CSVOptions{}).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	discount := 0.1
+	assert.Equal(t, []csvOrder{
+		{ID: 1, Customer: "alice", Amount: 10.5, Paid: true, Placed: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+			Delay: 90 * time.Minute, Discount: &discount, Status: 1},
+		{ID: 2, Customer: "bob", Amount: 20, Placed: time.Date(2024, 1, 3, 11, 0, 0, 0, time.UTC), Status: 2},
+	}, orders, "wrong orders")
+}
+
+func TestFromCSVHeaderMapping(t *testing.T) {
+	// prepare
+	type person struct {
+		Name string
+		Age  int `csv:"age"`
+	}
+	reader := strings.NewReader("\uFEFFunknown, age ,Name\nx,30,ann\ny,40,joe\n")
+
+	// call
+	people, err := FromCSV[person](reader, CSVOptions{}).Filter(func(p person) bool { return p.Age > 35 }).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []person{{"joe", 40}}, people, "wrong people")
+}
+
+func TestFromCSVNoHeader(t *testing.T) {
+	// prepare
+	type point struct {
+		X, Y int
+	}
+	reader := strings.NewReader("1;2\n# comment\n3;4\n")
+
+	// call
+	points, err := FromCSV[point](reader, CSVOptions{Comma: ';', Comment: '#', NoHeader: true}).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []point{{1, 2}, {3, 4}}, points, "wrong points")
+}
+
+func TestFromCSVStopOnError(t *testing.T) {
+	// prepare
+	reader := strings.NewReader("id,customer\n1,alice\nx,bob\n3,carol\n")
+
+	// call
+	s := FromCSV[csvOrder](reader, CSVOptions{})
+	orders, err := s.ToSlice()
+
+	// assert
+	var recordErr *RecordError
+	require.ErrorAs(t, err, &recordErr)
+	assert.Equal(t, 2, recordErr.Record, "wrong record")
+	assert.Equal(t, "id", recordErr.Field, "wrong field")
+	assert.ErrorIs(t, err, strconv.ErrSyntax, "wrong cause")
+	assert.Equal(t, err, s.Err(), "wrong stream error")
+	assert.Equal(t, []csvOrder{{ID: 1, Customer: "alice"}}, orders, "wrong orders before the error")
+}
+
+func TestFromCSVCollectErrors(t *testing.T) {
+	// prepare
+	reader := strings.NewReader("id,status\n1,open\n2,lost\n3\n4,closed\n")
+	var errs []*RecordError
+
+	// call
+	ids, err := LazyMap(FromCSV[csvOrder](reader, CSVOptions{OnError: CollectErrors(&errs)}),
+		func(o csvOrder) int { return o.ID }).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 4}, ids, "wrong ids")
+	require.Len(t, errs, 2, "wrong number of errors")
+	assert.EqualError(t, errs[0], `strm: record 2, field status: unknown status "lost"`, "wrong error")
+	assert.Equal(t, 3, errs[1].Record, "wrong record")
+	assert.ErrorIs(t, errs[1], csv.ErrFieldCount, "wrong error")
+}
+
+func TestFromCSVNotStruct(t *testing.T) {
+	// call
+	_, err := FromCSV[int](strings.NewReader("1\n"), CSVOptions{}).ToSlice()
+
+	// assert
+	assert.EqualError(t, err, "strm: CSV elements must be structs, not int", "wrong error")
+}
+
+func TestToCSV(t *testing.T) {
+	// prepare
+	discount := 0.25
+	orders := []csvOrder{
+		{ID: 1, Customer: "alice", Amount: 10.5, Paid: true, Placed: time.Date(2024, 1, 2, 10, 0, 0, 500, time.UTC),
+			Delay: 90 * time.Minute, Discount: &discount, Status: 1, internal: "x", Ignored: "y"},
+		{ID: 2, Customer: "bob, jr", Amount: 20, Status: 2},
+	}
+	var buf bytes.Buffer
+
+	// call
+	err := From(orders).Filter(func(o csvOrder) bool { return o.ID > 0 }).ToCSV(&buf)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, "id,customer,amount,paid,placed,delay,discount,status\n"+
+		"1,alice,10.5,true,2024-01-02T10:00:00Z,1h30m0s,0.25,open\n"+
+		"2,\"bob, jr\",20,false,0001-01-01T00:00:00Z,0s,,closed\n", buf.String(), "wrong CSV")
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	// prepare
+	var buf bytes.Buffer
+
+	// call
+	err := FromCSV[csvOrder](strings.NewReader(ordersCSV), CSVOptions{}).ToCSV(&buf)
+	orders, readErr := FromCSV[csvOrder](&buf, CSVOptions{}).ToSlice()
+	expected, _ := FromCSV[csvOrder](strings.NewReader(ordersCSV), CSVOptions{}).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	require.NoError(t, readErr)
+	assert.Equal(t, expected, orders, "wrong round trip")
+}
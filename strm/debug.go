@@ -0,0 +1,10 @@
+//go:build !strmdebug
+
+package strm
+
+// debugMode Enables the detection of concurrent uses of a Stream, with the strmdebug build tag
+const debugMode = false
+
+func goroutineID() int64 {
+	return 0
+}
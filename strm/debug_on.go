@@ -0,0 +1,21 @@
+//go:build strmdebug
+
+package strm
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// debugMode Enables the detection of concurrent uses of a Stream, with the strmdebug build tag
+const debugMode = true
+
+// returns the id of the calling goroutine, parsed from its stack trace header: "goroutine 42 [running]:"
+func goroutineID() int64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	id, _ := strconv.ParseInt(string(buf[:bytes.IndexByte(buf, ' ')]), 10, 64)
+	return id
+}
@@ -0,0 +1,164 @@
+package strm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Plan A structured description of a Stream, as returned by Explain: its source and the operations applied to it
+type Plan struct {
+	Source Source
+	// Stages the operations applied to the Stream and to the Streams it was derived from, in order
+	Stages []Stage
+	// PendingFilters the number of lazy filters not applied yet, waiting for the next operation
+	PendingFilters int
+	// CopyOnWrite true while the backing slice is still shared with the caller, see From
+	CopyOnWrite bool
+	State       State
+}
+
+// Source The origin of a Stream's elements
+type Source struct {
+	// Kind one of "slice" (From), "mutable slice" (FromMutable), "copy" (CopyFrom), "elements" (Of), "range", "merge" or
+	// "lazy" (LazyStream.Collect).
+	// Streams derived by operations like Map keep the Kind of their original Stream
+	Kind string
+	// Size the number of elements of the original Stream
+	Size int
+}
+
+// Stage An operation applied to a Stream
+type Stage struct {
+	Name     string
+	Terminal bool
+	// Lazy true for operations only recorded until the next non-lazy one, like Filter
+	Lazy bool
+	// ShortCircuit true for operations which may stop before processing all elements, like Take or Any
+	ShortCircuit bool
+	// In the number of elements before the operation, including the ones discarded by pending filters
+	In int
+	// Out the number of elements after the operation, or -1 if unknown, e.g. for lazy operations
+	Out int
+	// Goroutines the number of goroutines launched by parallel operations, like PMap
+	Goroutines int
+	// Batched true if a parallel operation batched its work by the number of logical CPUs
+	Batched bool
+}
+
+// Explain Returns a description of this Stream: its source, the operations applied to it so far, including the
+// ones applied to the Streams it was derived from, and its pending filters. Explain isn't an operation on the Stream,
+// so it may be called at any time, even after the Stream is consumed.
+func (s *Stream[T]) Explain() Plan {
+	return Plan{
+		Source:         s.plan.source,
+		Stages:         s.plan.history(s.plan.len()),
+		PendingFilters: len(s.filters),
+		CopyOnWrite:    s.shared,
+		State:          s.lifecycle.state,
+	}
+}
+
+// String Returns a multi-line rendering of this Plan, e.g. for logging
+func (p Plan) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "source: %s (%d elements)", p.Source.Kind, p.Source.Size)
+	if p.CopyOnWrite {
+		sb.WriteString(", copy-on-write")
+	}
+	sb.WriteString("\n")
+
+	for i, stage := range p.Stages {
+		fmt.Fprintf(&sb, "  %d. %s", i+1, stage.Name)
+		if stage.Lazy {
+			sb.WriteString(" lazy")
+		} else if stage.Out >= 0 {
+			fmt.Fprintf(&sb, " %d -> %d", stage.In, stage.Out)
+		} else {
+			fmt.Fprintf(&sb, " %d -> ?", stage.In)
+		}
+		if stage.Goroutines > 0 {
+			mode := "per-element"
+			if stage.Batched {
+				mode = "batched"
+			}
+			fmt.Fprintf(&sb, ", parallel %s on %d goroutines", mode, stage.Goroutines)
+		}
+		if stage.ShortCircuit {
+			sb.WriteString(", short-circuit")
+		}
+		if stage.Terminal {
+			sb.WriteString(", terminal")
+		}
+		sb.WriteString("\n")
+	}
+
+	fmt.Fprintf(&sb, "pending filters: %d\nstate: %s", p.PendingFilters, p.State)
+	return sb.String()
+}
+
+/*
+ * Internal Ops
+ */
+
+// the source and the recorded operations of a Stream. The operations recorded by the Streams it was derived from
+// are shared with them, rather than copied by each derived Stream.
+type plan struct {
+	source Source
+	// the plan of the Stream this one was derived from, and the number of its operations preceding this Stream
+	parent *plan
+	base   int
+	// the operations applied to this Stream
+	stages []Stage
+}
+
+// returns the number of operations of this plan, including the ones of the Streams it was derived from
+func (p *plan) len() int {
+	return p.base + len(p.stages)
+}
+
+// returns a copy of the first [n] operations of this plan
+func (p *plan) history(n int) []Stage {
+	var stages []Stage
+	if p.parent != nil {
+		stages = p.parent.history(min(n, p.base))
+	} else {
+		stages = make([]Stage, 0, n)
+	}
+	if own := n - p.base; own > 0 {
+		stages = append(stages, p.stages[:own]...)
+	}
+	return stages
+}
+
+// records the start of the given outermost operation
+func (s *Stream[T]) recordStage(name string, terminal bool) {
+	stage := Stage{Name: name, Terminal: terminal, In: len(s.slice), Out: -1}
+	switch name {
+	case "Filter", "SampleFraction":
+		stage.Lazy = true
+	case "Take", "Any", "All", "None", "First", "FirstBy", "Contains":
+		stage.ShortCircuit = true
+	}
+	s.plan.stages = append(s.plan.stages, stage)
+}
+
+// returns the stage of the operation in progress, or nil if there's none
+func (s *Stream[T]) currentStage() *Stage {
+	if s.lifecycle.depth == 0 || len(s.plan.stages) == 0 {
+		return nil
+	}
+	return &s.plan.stages[len(s.plan.stages)-1]
+}
+
+// creates a new Stream owning the given backing slice, derived by an operation from the given [parent] Stream:
+// it inherits the source and the recorded operations of its parent
+func derive[IN any, OUT any](parent *Stream[IN], slice []OUT) *Stream[OUT] {
+	if stage := parent.currentStage(); stage != nil {
+		stage.Out = len(slice)
+	}
+	s := newStream(slice, parent.plan.source.Kind)
+	s.plan.source.Size = parent.plan.source.Size
+	s.plan.parent, s.plan.base = parent.plan, parent.plan.len()
+	s.observation.observer = parent.observation.observer
+	return s
+}
@@ -0,0 +1,109 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	// prepare
+	s := From([]int{1, 2, 3, 4, 5, 6}).
+		Filter(func(n int) bool { return n%2 == 0 }).
+		Take(2).
+		Filter(func(n int) bool { return n > 2 })
+
+	// call
+	plan := s.Explain()
+
+	// assert
+	assert.Equal(t, Source{Kind: "slice", Size: 6}, plan.Source, "wrong source")
+	assert.Equal(t, []Stage{
+		{Name: "Filter", Lazy: true, In: 6, Out: -1},
+		{Name: "Take", ShortCircuit: true, In: 6, Out: 2},
+		{Name: "Filter", Lazy: true, In: 2, Out: -1},
+	}, plan.Stages, "wrong stages")
+	assert.Equal(t, 1, plan.PendingFilters, "wrong pending filters")
+	assert.False(t, plan.CopyOnWrite, "filtering should copy the shared slice")
+	assert.Equal(t, StateOpen, plan.State, "wrong state")
+}
+
+func TestExplainTerminalOp(t *testing.T) {
+	// prepare
+	s := Of(1, 2, 3, 4).Filter(func(n int) bool { return n > 1 })
+
+	// call
+	count := s.Count()
+	plan := s.Explain()
+
+	// assert
+	assert.Equal(t, 3, count, "wrong count")
+	assert.Equal(t, Stage{Name: "Count", Terminal: true, In: 4, Out: 3}, plan.Stages[1], "wrong terminal stage")
+	assert.Equal(t, 0, plan.PendingFilters, "wrong pending filters")
+	assert.Equal(t, StateConsumed, plan.State, "wrong state")
+}
+
+func TestExplainDerivedStream(t *testing.T) {
+	// prepare
+	s := RangeOf(1, 2, 3).Filter(func(n int) bool { return n != 2 })
+
+	// call
+	plan := Map(s.Stream, func(n int) string { return strings.Repeat("x", n) }).Distinct().Explain()
+
+	// assert
+	assert.Equal(t, Source{Kind: "elements", Size: 3}, plan.Source, "wrong source")
+	assert.Equal(t, []string{"Filter", "Map", "Distinct"}, stageNames(plan), "wrong stages")
+	assert.Equal(t, Stage{Name: "Map", Terminal: true, In: 3, Out: 2}, plan.Stages[1], "wrong map stage")
+	assert.False(t, plan.CopyOnWrite, "wrong copy-on-write")
+}
+
+func TestExplainSharesDerivedHistory(t *testing.T) {
+	// prepare
+	s := Of(1, 2, 3).Filter(func(n int) bool { return n > 1 })
+
+	// call
+	mapped := Map(s, func(n int) int { return n * 10 })
+	reduced := Map(mapped, func(n int) string { return strings.Repeat("x", n) })
+
+	// assert
+	assert.Same(t, mapped.plan, reduced.plan.parent, "the history should be shared, not copied")
+	assert.Empty(t, reduced.plan.stages, "no stage should be copied")
+	assert.Equal(t, []string{"Filter", "Map", "Map"}, stageNames(reduced.Explain()), "wrong stages")
+	assert.Equal(t, []string{"Filter", "Map"}, stageNames(s.Explain()), "the parent stages shouldn't change")
+}
+
+func TestExplainParallelStage(t *testing.T) {
+	// prepare
+	s := FromMutable(make([]int, 1000))
+
+	// call
+	plan := PMap(s, func(n int) int { return n + 1 }).Explain()
+
+	// assert
+	assert.Equal(t, "PMap", plan.Stages[0].Name, "wrong stage")
+	assert.Equal(t, 1000, plan.Stages[0].Out, "wrong stage output")
+	assert.Positive(t, plan.Stages[0].Goroutines, "wrong goroutines")
+}
+
+func TestPlanString(t *testing.T) {
+	// prepare
+	s := From([]int{3, 1, 2}).Filter(func(n int) bool { return n > 1 })
+	s.Any(func(n int) bool { return n == 2 })
+
+	// call
+	rendered := s.Explain().String()
+
+	// assert
+	assert.Equal(t, "source: slice (3 elements)\n"+
+		"  1. Filter lazy\n"+
+		"  2. Any 3 -> 2, short-circuit, terminal\n"+
+		"pending filters: 0\n"+
+		"state: consumed", rendered, "wrong rendering")
+}
+
+func stageNames(plan Plan) (names []string) {
+	for _, stage := range plan.Stages {
+		names = append(names, stage.Name)
+	}
+	return
+}
@@ -0,0 +1,176 @@
+package strm
+
+import (
+	"errors"
+	"sync"
+)
+
+// Tee Splits the given LazyStream into [n] LazyStreams, each receiving all its elements, e.g. for feeding
+// metrics, archiving and alerting from the same source. The source is pulled by another goroutine once a branch is
+// pulled, each branch buffering up to [bufferSize] elements: a branch whose buffer is full makes the others wait,
+// hence the branches are meant to be pulled concurrently, and every branch must be either pulled or closed.
+// The error of the source is returned by the Err of every branch. The given LazyStream is consumed, see LazyMap.
+func Tee[T any](s *LazyStream[T], n int, bufferSize int) []*LazyStream[T] {
+	s.handOver("Tee")
+	return newSplitter(s, n, bufferSize, func(T) (int, int) { return 0, n })
+}
+
+// PartitionTo Splits the given LazyStream into [n] LazyStreams, routing each element to a single one by the hash of
+// the key returned by the given [keyFn]: the elements of equal keys are received by the same branch, in order.
+// Keys are hashed like the sketches hash elements, so a key is routed to the same branch by every process.
+// Branches are pulled and buffered as by Tee, each one buffering up to [bufferSize] elements.
+func PartitionTo[T any, K comparable](s *LazyStream[T], n int, keyFn func(T) K, bufferSize int) []*LazyStream[T] {
+	s.handOver("PartitionTo")
+	hasher := newElemHasher[K]()
+	return newSplitter(s, n, bufferSize, func(elem T) (int, int) {
+		i := int(hasher.hash(keyFn(elem)) % uint64(n))
+		return i, i + 1
+	})
+}
+
+// FanIn Returns a new LazyStream of the elements of all the given LazyStreams, interleaved as they produce them, e.g.
+// for merging channel-backed sources. Each source is pulled by its own goroutine, and the returned LazyStream ends once
+// all of them are exhausted, returning the first of their errors from Err. The sources are released with the returned
+// LazyStream, interrupting their pending reads if it stopped early, see Debounce.
+// The given LazyStreams are consumed.
+func FanIn[T any](streams ...*LazyStream[T]) *LazyStream[T] {
+	for _, s := range streams {
+		s.handOver("FanIn")
+	}
+	merged := &LazyStream[T]{}
+	merged.src = &lazySource{closer: func() error {
+		var errs []error
+		for _, s := range streams {
+			errs = append(errs, s.src.release())
+			s.src.stop()
+		}
+		return errors.Join(errs...)
+	}}
+	merged.seq = func(yield func(T) bool) {
+		out, done := make(chan T), make(chan struct{})
+		defer close(done)
+		var wg sync.WaitGroup
+		for _, s := range streams {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer s.src.finish()
+				for elem := range s.seq {
+					select {
+					case out <- elem:
+					case <-done:
+						return
+					}
+				}
+				if err := s.src.error(); err != nil {
+					merged.src.fail(err)
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(out)
+		}()
+		for elem := range out {
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+	return merged
+}
+
+/*
+ * Internal Ops
+ */
+
+// distributes the elements of a LazyStream to branches, see Tee
+type splitter[T any] struct {
+	s *LazyStream[T]
+	// returns the range of the branches receiving the given element
+	route    func(elem T) (from int, to int)
+	branches []*LazyStream[T]
+	outs     []chan T
+	detached []chan struct{}
+
+	mu       sync.Mutex
+	started  bool
+	detaches int
+}
+
+func newSplitter[T any](s *LazyStream[T], n int, bufferSize int, route func(T) (int, int)) []*LazyStream[T] {
+	if n <= 0 {
+		panic("strm: the number of branches must be positive")
+	}
+	sp := &splitter[T]{s: s, route: route}
+	for i := 0; i < n; i++ {
+		out, detached := make(chan T, max(bufferSize, 0)), make(chan struct{})
+		branch := &LazyStream[T]{}
+		branch.src = &lazySource{closer: func() error { return sp.detach(detached) }}
+		branch.seq = func(yield func(T) bool) {
+			sp.start()
+			for elem := range out {
+				if !yield(elem) {
+					return
+				}
+			}
+		}
+		sp.branches, sp.outs, sp.detached = append(sp.branches, branch), append(sp.outs, out), append(sp.detached, detached)
+	}
+	return sp.branches
+}
+
+// starts distributing the elements of the source, once
+func (sp *splitter[T]) start() {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	if !sp.started {
+		sp.started = true
+		go sp.run()
+	}
+}
+
+// stops sending elements to the given branch, closing the source once all branches are detached before it started
+func (sp *splitter[T]) detach(detached chan struct{}) error {
+	close(detached)
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	if sp.detaches++; sp.detaches == len(sp.branches) && !sp.started {
+		return sp.s.src.release()
+	}
+	return nil
+}
+
+// sends the elements of the source to the branches, until all of them are detached
+func (sp *splitter[T]) run() {
+	sp.distribute()
+	sp.s.src.finish()
+	err := sp.s.src.error()
+	for i, branch := range sp.branches {
+		if err != nil {
+			branch.src.fail(err)
+		}
+		close(sp.outs[i])
+	}
+}
+
+func (sp *splitter[T]) distribute() {
+	for elem := range sp.s.seq {
+		from, to := sp.route(elem)
+		for i := from; i < to; i++ {
+			select {
+			case sp.outs[i] <- elem:
+			case <-sp.detached[i]:
+			}
+		}
+		if sp.allDetached() {
+			return
+		}
+	}
+}
+
+func (sp *splitter[T]) allDetached() bool {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return sp.detaches == len(sp.branches)
+}
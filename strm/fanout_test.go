@@ -0,0 +1,227 @@
+package strm
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTee(t *testing.T) {
+	// prepare
+	branches := Tee(FromSeq(slices.Values([]int{1, 2, 3, 4, 5, 6})), 3, 2)
+	results := make([][]int, len(branches))
+
+	// call
+	var wg sync.WaitGroup
+	for i, branch := range branches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = branch.ToSlice()
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	for _, result := range results {
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, result, "every branch should receive all elements")
+	}
+}
+
+func TestTeeDetachedBranches(t *testing.T) {
+	// prepare
+	var pulled atomic.Int32
+	source := FromSeq(func(yield func(int) bool) {
+		for i := 0; i < 1000; i++ {
+			pulled.Add(1)
+			if !yield(i) {
+				return
+			}
+		}
+	})
+	branches := Tee(source, 3, 0)
+
+	// call
+	closeErr := branches[2].Close()
+	var first, sum int
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		first, _ = branches[0].First()
+	}()
+	go func() {
+		defer wg.Done()
+		branches[1].Take(10).ForEach(func(n int) { sum += n })
+	}()
+	wg.Wait()
+
+	// assert
+	assert.NoError(t, closeErr)
+	assert.Equal(t, 0, first, "wrong first element")
+	assert.Equal(t, 45, sum, "wrong sum of the first elements")
+	assert.Less(t, pulled.Load(), int32(1000), "the source should stop once all branches are detached")
+}
+
+func TestTeeError(t *testing.T) {
+	// prepare
+	readErr := errors.New("disk failure")
+	branches := Tee(Lines(io.MultiReader(strings.NewReader("a\nb\n"), &failingReader{readErr})), 2, 4)
+
+	// call
+	var wg sync.WaitGroup
+	counts := make([]int, 2)
+	for i, branch := range branches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counts[i] = branch.Count()
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assert.Equal(t, []int{2, 2}, counts, "wrong counts before the error")
+	assert.ErrorIs(t, branches[0].Err(), readErr, "wrong error")
+	assert.ErrorIs(t, branches[1].Err(), readErr, "wrong error")
+}
+
+func TestTeeClosedBeforeStart(t *testing.T) {
+	// prepare
+	var closes atomic.Int32
+	branches := Tee(closing(&closes, 1, 2), 2, 1)
+
+	// call
+	for _, branch := range branches {
+		require.NoError(t, branch.Close())
+	}
+
+	// assert
+	assert.Equal(t, int32(1), closes.Load(), "the source should be closed with all its branches")
+}
+
+func TestPartitionTo(t *testing.T) {
+	// prepare
+	words := []string{"apple", "avocado", "banana", "blueberry", "cherry", "apricot", "coconut"}
+	partitions := PartitionTo(FromSeq(slices.Values(words)), 3, func(w string) byte { return w[0] }, 2)
+	results := make([][]string, len(partitions))
+
+	// call
+	var wg sync.WaitGroup
+	for i, partition := range partitions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = partition.ToSlice()
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	var all []string
+	for _, result := range results {
+		all = append(all, result...)
+	}
+	assert.ElementsMatch(t, words, all, "every element should be routed once")
+	for _, result := range results {
+		if slices.Contains(result, "apple") {
+			assert.Equal(t, []string{"apple", "avocado", "apricot"}, filterPrefix(result, "a"), "equal keys should share a partition, in order")
+		}
+	}
+}
+
+func TestPartitionToIsStable(t *testing.T) {
+	// prepare
+	keys := []string{"a", "b", "user-1", "user-2", "user-3"}
+	partitions := PartitionTo(FromSeq(slices.Values(keys)), 4, func(k string) string { return k }, len(keys))
+	results := make([][]string, len(partitions))
+
+	// call
+	var wg sync.WaitGroup
+	for i, partition := range partitions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = partition.ToSlice()
+		}()
+	}
+	wg.Wait()
+
+	// assert: the routing doesn't depend on the process
+	assert.Equal(t, [][]string{{"a", "user-1"}, {"b"}, {"user-3"}, {"user-2"}}, results, "keys should be pinned to their partitions")
+}
+
+func TestFanIn(t *testing.T) {
+	// prepare
+	ch1, ch2 := make(chan int), make(chan int)
+	go func() {
+		defer close(ch1)
+		for i := 0; i < 5; i++ {
+			ch1 <- i
+		}
+	}()
+	go func() {
+		defer close(ch2)
+		for i := 100; i < 105; i++ {
+			ch2 <- i
+		}
+	}()
+
+	// call
+	merged, err := FanIn(FromChan(ch1), FromChan(ch2), FromSeq(slices.Values([]int{1000}))).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 100, 101, 102, 103, 104, 1000}, merged, "wrong merged elements")
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, filterBelow(merged, 100), "the order of each source should be preserved")
+}
+
+func TestFanInErrorsAndClose(t *testing.T) {
+	// prepare
+	readErr := errors.New("disk failure")
+	failing := Lines(&failingReader{readErr})
+	var closes atomic.Int32
+	unpulled := closing(&closes, 1)
+
+	// call
+	_, err := FanIn(Lines(strings.NewReader("a\n")), failing).ToSlice()
+	closeErr := FanIn(unpulled).Close()
+
+	// assert
+	assert.ErrorIs(t, err, readErr, "wrong error")
+	assert.NoError(t, closeErr)
+	assert.Equal(t, int32(1), closes.Load(), "sources should be closed with the merged stream")
+	assert.Equal(t, StateConsumed, unpulled.State(), "wrong state")
+}
+
+func TestFanInStopEarly(t *testing.T) {
+	// prepare
+	blocked, interrupted := blockedLines()
+
+	// call
+	first, ok := FanIn(blocked, FromSeq(slices.Values([]string{"a", "b"}))).First()
+
+	// assert
+	assert.True(t, ok, "an element should be found")
+	assert.Equal(t, "a", first, "wrong first element")
+	select {
+	case <-interrupted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the pending read of the blocked source should be interrupted")
+	}
+}
+
+func filterPrefix(words []string, prefix string) []string {
+	return slices.DeleteFunc(slices.Clone(words), func(w string) bool { return !strings.HasPrefix(w, prefix) })
+}
+
+func filterBelow(nums []int, limit int) []int {
+	return slices.DeleteFunc(slices.Clone(nums), func(n int) bool { return n >= limit })
+}
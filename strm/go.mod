@@ -1,6 +1,6 @@
 module github.com/pscosta/go-strm/strm
 
-go 1.21
+go 1.23
 
 require (
 	// hashing non-comparable types
@@ -15,13 +15,19 @@ type IntStream struct {
 // [from] (inclusive) to [to] (inclusive) by an incremental step of 1.
 func Range(from int, to int) *IntStream {
 	if to < from {
-		return &IntStream{From([]int{})}
+		return &IntStream{newStream([]int{}, "range")}
 	}
 	intSlice := make([]int, 0, to-from)
 	for i := from; i <= to; i++ {
 		intSlice = append(intSlice, i)
 	}
-	return &IntStream{From(intSlice)}
+	return &IntStream{newStream(intSlice, "range")}
+}
+
+// RangeStep Returns a sequential ordered IntStream from [from] (inclusive) to [to] (inclusive)
+// by the given [step]. A negative [step] produces a descending IntStream, when [to] is lower than [from].
+func RangeStep(from int, to int, step int) *IntStream {
+	return &IntStream{NumRange(from, to, step).Stream}
 }
 
 // RangeOf Creates a new IntStream backed by the given [elems]
@@ -30,11 +36,11 @@ func RangeOf(elems ...int) *IntStream {
 	for _, elem := range elems {
 		intSlice = append(intSlice, elem)
 	}
-	return &IntStream{From(intSlice)}
+	return &IntStream{newStream(intSlice, "elements")}
 }
 
 // RangeFrom Creates a new IntStream backed by the given [backingSlice]
-// the state of the given backingSlice will be updated by operation applied to the returned IntStream
+// the state of the given backingSlice will be preserved, see From
 func RangeFrom(backingIntSlice []int) *IntStream {
 	return &IntStream{From(backingIntSlice)}
 }
@@ -51,6 +57,7 @@ func RangeCopyFrom(backingIntSlice []int) *IntStream {
 
 // Sum Returns the sum of elements in this IntStream
 func (s *IntStream) Sum() (sum int) {
+	defer s.exit(s.enter("Sum", true))
 	if len(s.filteredSlice()) == 0 {
 		return
 	}
@@ -62,6 +69,7 @@ func (s *IntStream) Sum() (sum int) {
 
 // Min Returns the minimum element of this IntStream, or 0 if this IntStream is empty
 func (s *IntStream) Min() int {
+	defer s.exit(s.enter("Min", true))
 	if len(s.filteredSlice()) == 0 {
 		return 0
 	}
@@ -76,6 +84,7 @@ func (s *IntStream) Min() int {
 
 // Max Returns the maximum element of this IntStream, or 0 if this IntStream is empty
 func (s *IntStream) Max() int {
+	defer s.exit(s.enter("Max", true))
 	if len(s.filteredSlice()) == 0 {
 		return 0
 	}
@@ -90,17 +99,45 @@ func (s *IntStream) Max() int {
 
 // Avg Returns the arithmetic mean of elements of this IntStream, or 0 if this IntStream is empty
 func (s *IntStream) Avg() int {
+	defer s.exit(s.enter("Avg", true))
 	if len(s.filteredSlice()) == 0 {
 		return 0
 	}
 	return s.Sum() / len(s.slice)
 }
 
+// SummaryStatistics see NumStream.SummaryStatistics
+func (s *IntStream) SummaryStatistics() Statistics[int] {
+	return AsNums(s.Stream).SummaryStatistics()
+}
+
+// Median see NumStream.Median
+func (s *IntStream) Median() float64 {
+	return AsNums(s.Stream).Median()
+}
+
+// Percentile see NumStream.Percentile
+func (s *IntStream) Percentile(p float64) float64 {
+	return AsNums(s.Stream).Percentile(p)
+}
+
+// Quantiles see NumStream.Quantiles
+func (s *IntStream) Quantiles(n int) []float64 {
+	return AsNums(s.Stream).Quantiles(n)
+}
+
+// Mode see NumStream.Mode
+func (s *IntStream) Mode() int {
+	return AsNums(s.Stream).Mode()
+}
+
 // Sorted sorts the IntStream in increasing order.
 func (s *IntStream) Sorted() *IntStream {
+	defer s.exit(s.enter("Sorted", false))
 	if len(s.filteredSlice()) == 0 {
 		return s
 	}
+	s.own()
 	sort.Ints(s.Stream.slice)
 	return s
 }
@@ -83,3 +83,15 @@ func TestSorted(t *testing.T) {
 	// assert
 	assert.Equal(t, []int{1, 2, 3}, max, "wrong sorting")
 }
+
+func TestRangeStep(t *testing.T) {
+	// call
+	ascending := RangeStep(1, 10, 3).ToSlice()
+	descending := RangeStep(5, 1, -2).ToSlice()
+	unreachable := RangeStep(5, 1, 2).ToSlice()
+
+	// assert
+	assert.Equal(t, []int{1, 4, 7, 10}, ascending, "wrong range")
+	assert.Equal(t, []int{5, 3, 1}, descending, "wrong range")
+	assert.Equal(t, 0, len(unreachable), "wrong length")
+}
@@ -0,0 +1,173 @@
+package strm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// FromJSONLines Creates a new LazyStream of the JSON values read from the given [reader], one per line
+// (JSON Lines / NDJSON), decoded into T. Blank lines are ignored, and lines aren't limited in length.
+// Lines which can't be decoded are reported as RecordError, their Record being the line number,
+// to the given [onError] policy, StopOnError when nil.
+func FromJSONLines[T any](reader io.Reader, onError ErrorPolicy) *LazyStream[T] {
+	s := &LazyStream[T]{src: &lazySource{}}
+	s.seq = func(yield func(T) bool) {
+		r := bufio.NewReader(reader)
+		for line := 1; ; line++ {
+			data, err := r.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				s.src.fail(err)
+				return
+			}
+			if data = bytes.TrimSpace(data); len(data) > 0 {
+				var elem T
+				if decodeErr := json.Unmarshal(data, &elem); decodeErr != nil {
+					recordErr := &RecordError{Record: line, Field: jsonField(decodeErr), Err: decodeErr}
+					if !onError.skip(recordErr) {
+						s.src.fail(recordErr)
+						return
+					}
+				} else if !yield(elem) {
+					return
+				}
+			}
+			if err == io.EOF {
+				return
+			}
+		}
+	}
+	return s
+}
+
+// FromJSONArray Creates a new LazyStream of the elements of the top-level JSON array read from the given [reader],
+// decoded into T one at a time, so that huge arrays are never held in memory.
+// Elements which can't be decoded into T are reported as RecordError, their Record being their position in
+// the array starting at 1, to the given [onError] policy, StopOnError when nil. Malformed JSON always stops
+// the LazyStream, as the following elements can't be found.
+func FromJSONArray[T any](reader io.Reader, onError ErrorPolicy) *LazyStream[T] {
+	s := &LazyStream[T]{src: &lazySource{}}
+	s.seq = func(yield func(T) bool) {
+		decoder := json.NewDecoder(reader)
+		if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+			if err == nil || err == io.EOF {
+				err = fmt.Errorf("strm: expected a JSON array, got %v", token)
+			}
+			s.src.fail(err)
+			return
+		}
+		for record := 1; decoder.More(); record++ {
+			var elem T
+			if err := decoder.Decode(&elem); err != nil {
+				recordErr := &RecordError{Record: record, Field: jsonField(err), Err: err}
+				var typeErr *json.UnmarshalTypeError
+				// after a type error the decoder still reached the end of the element
+				if errors.As(err, &typeErr) && onError.skip(recordErr) {
+					continue
+				}
+				s.src.fail(recordErr)
+				return
+			}
+			if !yield(elem) {
+				return
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			s.src.fail(err)
+		}
+	}
+	return s
+}
+
+// ToJSONLines Writes the elements of this Stream to the given [writer] as JSON values, one per line
+func (s *Stream[T]) ToJSONLines(writer io.Writer) error {
+	defer s.exit(s.enter("ToJSONLines", true))
+	return writeJSON(writer, s.values(), false)
+}
+
+// ToJSONArray Writes the elements of this Stream to the given [writer] as a JSON array, one element at a time
+func (s *Stream[T]) ToJSONArray(writer io.Writer) error {
+	defer s.exit(s.enter("ToJSONArray", true))
+	return writeJSON(writer, s.values(), true)
+}
+
+// ToJSONLines Writes the elements of this LazyStream to the given [writer] as JSON values, one per line, as they
+// are pulled. The first error of the source is returned if writing didn't fail.
+func (s *LazyStream[T]) ToJSONLines(writer io.Writer) error {
+	s.use("ToJSONLines", true)
+	defer s.src.finish()
+	if err := writeJSON(writer, s.seq, false); err != nil {
+		return err
+	}
+	return s.src.error()
+}
+
+// ToJSONArray Writes the elements of this LazyStream to the given [writer] as a JSON array, as they are pulled.
+// The first error of the source is returned if writing didn't fail, the array being closed anyway.
+func (s *LazyStream[T]) ToJSONArray(writer io.Writer) error {
+	s.use("ToJSONArray", true)
+	defer s.src.finish()
+	if err := writeJSON(writer, s.seq, true); err != nil {
+		return err
+	}
+	return s.src.error()
+}
+
+/*
+ * Internal Ops
+ */
+
+// returns the elements of this Stream, after applying its pending filters, as an iterator
+func (s *Stream[T]) values() iter.Seq[T] {
+	slice := s.filteredSlice()
+	return func(yield func(T) bool) {
+		for _, elem := range slice {
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// writes the given elements as JSON values, one per line, or as a JSON array.
+// Write errors are kept by the buffered writer and returned by its final Flush.
+func writeJSON[T any](writer io.Writer, elems iter.Seq[T], array bool) error {
+	w := bufio.NewWriter(writer)
+	if array {
+		w.WriteByte('[')
+	}
+	first := true
+	for elem := range elems {
+		data, err := json.Marshal(elem)
+		if err != nil {
+			// the elements written before the failed one are kept
+			_ = w.Flush()
+			return err
+		}
+		switch {
+		case !array:
+			data = append(data, '\n')
+		case !first:
+			w.WriteByte(',')
+		}
+		w.Write(data)
+		first = false
+	}
+	if array {
+		w.WriteString("]\n")
+	}
+	return w.Flush()
+}
+
+// returns the field of the given JSON decoding error, if known
+func jsonField(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return typeErr.Field
+	}
+	return ""
+}
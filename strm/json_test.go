@@ -0,0 +1,175 @@
+package strm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+type jsonEvent struct {
+	ID   int    `json:"id"`
+	Kind string `json:"kind"`
+}
+
+func TestFromJSONLines(t *testing.T) {
+	// prepare
+	reader := strings.NewReader("{\"id\":1,\"kind\":\"click\"}\n\n  {\"id\":2,\"kind\":\"view\"}\r\n{\"id\":3,\"kind\":\"click\"}")
+
+	// call
+	clicks, err := FromJSONLines[jsonEvent](reader, nil).Filter(func(e jsonEvent) bool { return e.Kind == "click" }).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []jsonEvent{{1, "click"}, {3, "click"}}, clicks, "wrong events")
+}
+
+func TestFromJSONLinesLongLine(t *testing.T) {
+	// prepare
+	kind := strings.Repeat("x", 100_000)
+	reader := strings.NewReader(`{"id":1,"kind":"` + kind + "\"}\n")
+
+	// call
+	events, err := FromJSONLines[jsonEvent](reader, nil).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []jsonEvent{{1, kind}}, events, "wrong events")
+}
+
+func TestFromJSONLinesErrorPolicy(t *testing.T) {
+	// prepare
+	input := "{\"id\":1}\n{\"id\":\"two\"}\n{not json\n{\"id\":4}\n"
+	var errs []*RecordError
+
+	// call
+	skipped, skipErr := FromJSONLines[jsonEvent](strings.NewReader(input), CollectErrors(&errs)).ToSlice()
+	s := FromJSONLines[jsonEvent](strings.NewReader(input), StopOnError)
+	stopped, stopErr := s.ToSlice()
+
+	// assert
+	require.NoError(t, skipErr)
+	assert.Equal(t, []jsonEvent{{ID: 1}, {ID: 4}}, skipped, "wrong events")
+	require.Len(t, errs, 2, "wrong number of errors")
+	assert.Equal(t, 2, errs[0].Record, "wrong line")
+	assert.Equal(t, "id", errs[0].Field, "wrong field")
+	assert.Equal(t, 3, errs[1].Record, "wrong line")
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, errs[1], &syntaxErr, "wrong error")
+
+	assert.Equal(t, []jsonEvent{{ID: 1}}, stopped, "wrong events before the error")
+	assert.Equal(t, errs[0].Error(), stopErr.Error(), "wrong error")
+	assert.Equal(t, stopErr, s.Err(), "wrong stream error")
+}
+
+func TestFromJSONLinesReaderError(t *testing.T) {
+	// prepare
+	readErr := errors.New("connection reset")
+	reader := io.MultiReader(strings.NewReader("{\"id\":1}\n"), &failingReader{readErr})
+
+	// call
+	events, err := FromJSONLines[jsonEvent](reader, SkipInvalid).ToSlice()
+
+	// assert
+	assert.Equal(t, []jsonEvent{{ID: 1}}, events, "wrong events")
+	assert.ErrorIs(t, err, readErr, "wrong error")
+}
+
+func TestFromJSONArray(t *testing.T) {
+	// prepare
+	reader := strings.NewReader(` [ {"id":1,"kind":"click"}, {"id":2,"kind":"view"}, {"id":3} ] `)
+
+	// call
+	ids, err := LazyMap(FromJSONArray[jsonEvent](reader, nil).Drop(1), func(e jsonEvent) int { return e.ID }).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, ids, "wrong ids")
+}
+
+func TestFromJSONArrayStopsReading(t *testing.T) {
+	// prepare
+	reader := io.MultiReader(strings.NewReader(`[{"id":1},{"id":2},`), &failingReader{errors.New("unreachable")})
+
+	// call
+	first, ok := FromJSONArray[jsonEvent](reader, nil).First()
+
+	// assert
+	assert.True(t, ok, "missing first element")
+	assert.Equal(t, jsonEvent{ID: 1}, first, "wrong first element")
+}
+
+func TestFromJSONArrayErrors(t *testing.T) {
+	// prepare
+	var errs []*RecordError
+
+	// call
+	skipped, skipErr := FromJSONArray[jsonEvent](strings.NewReader(`[{"id":1},{"id":"x"},{"id":3}]`),
+		CollectErrors(&errs)).ToSlice()
+	malformed, malformedErr := FromJSONArray[jsonEvent](strings.NewReader(`[{"id":1},{"id":}]`), SkipInvalid).ToSlice()
+	_, notArrayErr := FromJSONArray[jsonEvent](strings.NewReader(`{"id":1}`), nil).ToSlice()
+	_, emptyErr := FromJSONArray[jsonEvent](strings.NewReader(``), nil).ToSlice()
+
+	// assert
+	require.NoError(t, skipErr)
+	assert.Equal(t, []jsonEvent{{ID: 1}, {ID: 3}}, skipped, "wrong events")
+	require.Len(t, errs, 1, "wrong number of errors")
+	assert.Equal(t, 2, errs[0].Record, "wrong record")
+
+	assert.Equal(t, []jsonEvent{{ID: 1}}, malformed, "wrong events before malformed JSON")
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, malformedErr, &syntaxErr, "malformed JSON should stop even when skipping")
+	assert.EqualError(t, notArrayErr, "strm: expected a JSON array, got {", "wrong error")
+	assert.EqualError(t, emptyErr, "strm: expected a JSON array, got <nil>", "wrong error")
+}
+
+func TestToJSON(t *testing.T) {
+	// prepare
+	events := []jsonEvent{{1, "click"}, {2, "view"}, {3, "click"}}
+	var lines, array, empty bytes.Buffer
+
+	// call
+	linesErr := From(events).Filter(func(e jsonEvent) bool { return e.Kind == "click" }).ToJSONLines(&lines)
+	arrayErr := From(events).Take(2).ToJSONArray(&array)
+	emptyErr := Of[jsonEvent]().ToJSONArray(&empty)
+
+	// assert
+	require.NoError(t, linesErr)
+	require.NoError(t, arrayErr)
+	require.NoError(t, emptyErr)
+	assert.Equal(t, "{\"id\":1,\"kind\":\"click\"}\n{\"id\":3,\"kind\":\"click\"}\n", lines.String(), "wrong JSON lines")
+	assert.Equal(t, "[{\"id\":1,\"kind\":\"click\"},{\"id\":2,\"kind\":\"view\"}]\n", array.String(), "wrong JSON array")
+	assert.Equal(t, "[]\n", empty.String(), "wrong empty JSON array")
+}
+
+func TestJSONLinesToArray(t *testing.T) {
+	// prepare
+	reader := strings.NewReader("{\"id\":1}\n{\"id\":2}\n")
+	var array bytes.Buffer
+
+	// call
+	err := FromJSONLines[jsonEvent](reader, nil).ToJSONArray(&array)
+	events, readErr := FromJSONArray[jsonEvent](&array, nil).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	require.NoError(t, readErr)
+	assert.Equal(t, []jsonEvent{{ID: 1}, {ID: 2}}, events, "wrong round trip")
+}
+
+func TestToJSONError(t *testing.T) {
+	// prepare
+	var buf bytes.Buffer
+
+	// call
+	err := Of[any](1, 2, func() {}).ToJSONLines(&buf)
+
+	// assert
+	var unsupported *json.UnsupportedTypeError
+	assert.ErrorAs(t, err, &unsupported, "wrong error")
+	assert.Equal(t, "1\n2\n", buf.String(), "the elements before the failed one should be written")
+}
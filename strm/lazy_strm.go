@@ -0,0 +1,304 @@
+package strm
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+)
+
+// LazyStream A single-use Stream pulling its elements one at a time from a source, e.g. a file or a network
+// connection, without holding them in memory. Operations are recorded until a terminal operation pulls the elements
+// through all of them. Errors of the source stop the LazyStream and are returned by Err.
+type LazyStream[T any] struct {
+	seq        iter.Seq[T]
+	src        *lazySource
+	state      State
+	consumedBy string
+	// whether the source was taken over by the LazyStream returned by the operation consuming this one
+	handedOver bool
+}
+
+/*
+ * Constructors
+ */
+
+// FromSeq Creates a new LazyStream pulling its elements from the given [seq]
+func FromSeq[T any](seq iter.Seq[T]) *LazyStream[T] {
+	return &LazyStream[T]{seq: seq, src: &lazySource{}}
+}
+
+// FromChan Creates a new LazyStream pulling its elements from the given channel [ch], until it's closed.
+// Terminal operations block while waiting for the elements of [ch].
+func FromChan[T any](ch <-chan T) *LazyStream[T] {
+	return FromSeq(func(yield func(T) bool) {
+		for elem := range ch {
+			if !yield(elem) {
+				return
+			}
+		}
+	})
+}
+
+// Lazy Returns a new LazyStream of the elements of this Stream, e.g. for sorting, grouping or deduplicating them with
+// the external-memory operations, like ExternalGroupBy, without holding their sorted copy in memory.
+// This Stream is consumed.
+func (s *Stream[T]) Lazy() *LazyStream[T] {
+	defer s.exit(s.enter("Lazy", true))
+	return FromSeq(slices.Values(s.filteredSlice()))
+}
+
+/*
+ * Main Ops
+ */
+
+// Filter Lazily filters the elements of this LazyStream with the given [predicate]
+func (s *LazyStream[T]) Filter(predicate func(T) bool) *LazyStream[T] {
+	s.use("Filter", false)
+	seq := s.seq
+	s.seq = func(yield func(T) bool) {
+		for elem := range seq {
+			if predicate(elem) && !yield(elem) {
+				return
+			}
+		}
+	}
+	return s
+}
+
+// OnEach Lazily calls the given [action] on each element of this LazyStream, as it's pulled
+func (s *LazyStream[T]) OnEach(action func(T)) *LazyStream[T] {
+	s.use("OnEach", false)
+	seq := s.seq
+	s.seq = func(yield func(T) bool) {
+		for elem := range seq {
+			action(elem)
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+	return s
+}
+
+// Take Lazily keeps the first [n] elements of this LazyStream, the source isn't read any further
+func (s *LazyStream[T]) Take(n int) *LazyStream[T] {
+	s.use("Take", false)
+	seq := s.seq
+	s.seq = func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for elem := range seq {
+			i++
+			if !yield(elem) || i >= n {
+				return
+			}
+		}
+	}
+	return s
+}
+
+// Drop Lazily skips the first [n] elements of this LazyStream
+func (s *LazyStream[T]) Drop(n int) *LazyStream[T] {
+	s.use("Drop", false)
+	seq := s.seq
+	s.seq = func(yield func(T) bool) {
+		i := 0
+		for elem := range seq {
+			i++
+			if i > n && !yield(elem) {
+				return
+			}
+		}
+	}
+	return s
+}
+
+// LazyMap Returns a new LazyStream lazily applying the given [mapper] to each element of the given LazyStream.
+// The given LazyStream is consumed: its source, errors and Close are taken over by the returned one.
+func LazyMap[T any, R any](s *LazyStream[T], mapper func(T) R) *LazyStream[R] {
+	s.handOver("LazyMap")
+	seq := s.seq
+	return &LazyStream[R]{src: s.src, seq: func(yield func(R) bool) {
+		for elem := range seq {
+			if !yield(mapper(elem)) {
+				return
+			}
+		}
+	}}
+}
+
+/*
+ * Terminal Ops
+ */
+
+// ForEach Pulls every element of this LazyStream, calling the given [action] on each of them.
+// Check Err for errors of the source.
+func (s *LazyStream[T]) ForEach(action func(T)) {
+	s.use("ForEach", true)
+	defer s.src.finish()
+	for elem := range s.seq {
+		action(elem)
+	}
+}
+
+// Count Pulls every element of this LazyStream and returns their count. Check Err for errors of the source.
+func (s *LazyStream[T]) Count() (count int) {
+	s.use("Count", true)
+	defer s.src.finish()
+	for range s.seq {
+		count++
+	}
+	return
+}
+
+// First Pulls the first element of this LazyStream, returning false if it's empty
+func (s *LazyStream[T]) First() (first T, ok bool) {
+	s.use("First", true)
+	defer s.src.finish()
+	for elem := range s.seq {
+		return elem, true
+	}
+	return
+}
+
+// ToSlice Pulls every element of this LazyStream into a new slice, returning the first error of the source
+// together with the elements pulled before it
+func (s *LazyStream[T]) ToSlice() ([]T, error) {
+	s.use("ToSlice", true)
+	defer s.src.finish()
+	var slice []T
+	for elem := range s.seq {
+		slice = append(slice, elem)
+	}
+	return slice, s.src.error()
+}
+
+// Collect Pulls every element of this LazyStream into a new Stream, for the operations needing all of them,
+// e.g. Sorted or GroupBy. The first error of the source is returned together with the elements pulled before it.
+func (s *LazyStream[T]) Collect() (*Stream[T], error) {
+	slice, err := s.ToSlice()
+	return newStream(slice, "lazy"), err
+}
+
+// Seq Returns the elements of this LazyStream as a single-use iterator, e.g. for ranging over them.
+// Check Err for errors of the source once the iteration ends.
+func (s *LazyStream[T]) Seq() iter.Seq[T] {
+	s.use("Seq", true)
+	return func(yield func(T) bool) {
+		defer s.src.finish()
+		for elem := range s.seq {
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+/*
+ * Lifecycle
+ */
+
+// Err Returns the first error of the source of this LazyStream, or nil if there's none or the source wasn't read
+func (s *LazyStream[T]) Err() error {
+	return s.src.error()
+}
+
+// State Returns the lifecycle State of this LazyStream
+func (s *LazyStream[T]) State() State {
+	return s.state
+}
+
+// Close Releases the resources of the source of this LazyStream, e.g. an opened file, without reading it any further.
+// Sources are also released once a terminal operation ends, so Close only needs deferring for LazyStreams which
+// may not reach their terminal operation. Close is idempotent and returns the error of releasing the resources.
+func (s *LazyStream[T]) Close() error {
+	if s.handedOver {
+		return nil
+	}
+	if s.state == StateOpen {
+		s.state = StateClosed
+	}
+	return s.src.release()
+}
+
+/*
+ * Internal Ops
+ */
+
+// the state shared by the LazyStreams pulling from the same source
+type lazySource struct {
+	// guards err and stopped, which may be set by the goroutine pulling the source of time-aware operations
+	mu    sync.Mutex
+	err   error
+	clock Clock
+	// whether the source was released, dropping its errors reported afterwards
+	stopped bool
+	// releases the resources of the source, called once
+	closer    func() error
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// checks the given operation may be applied, a terminal one consuming the LazyStream
+func (s *LazyStream[T]) use(name string, terminal bool) {
+	switch s.state {
+	case StateConsumed:
+		panic(fmt.Errorf("%w: %s called after %s", ErrStreamConsumed, name, s.consumedBy))
+	case StateClosed:
+		panic(fmt.Errorf("%w: %s called after Close", ErrStreamClosed, name))
+	}
+	if terminal {
+		s.state, s.consumedBy = StateConsumed, name
+	}
+}
+
+// consumes the LazyStream by the given operation, returning a new LazyStream which takes its source over
+func (s *LazyStream[T]) handOver(name string) {
+	s.use(name, true)
+	s.handedOver = true
+}
+
+// records the given error of the source, keeping the first one reported before the source is stopped
+func (src *lazySource) fail(err error) {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if src.err == nil && !src.stopped {
+		src.err = err
+	}
+}
+
+// returns the first error of the source
+func (src *lazySource) error() error {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	return src.err
+}
+
+// ends the terminal operation, releasing the source
+func (src *lazySource) finish() {
+	if err := src.release(); err != nil {
+		src.fail(err)
+	}
+	src.stop()
+}
+
+// drops the errors reported by the source from now on, e.g. by a goroutine still reading a released source
+func (src *lazySource) stop() {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	src.stopped = true
+}
+
+// releases the resources of the source once, returning the error of releasing them. Releasing a source being read by
+// another goroutine, e.g. closing a file, makes its pending read fail.
+func (src *lazySource) release() error {
+	src.closeOnce.Do(func() {
+		if src.closer != nil {
+			src.closeErr = src.closer()
+		}
+	})
+	return src.closeErr
+}
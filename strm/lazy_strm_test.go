@@ -0,0 +1,137 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLazyStreamOps(t *testing.T) {
+	// prepare
+	var seen []int
+	s := FromSeq(slices.Values([]int{1, 2, 3, 4, 5, 6, 7, 8}))
+
+	// call
+	evens := s.Drop(1).
+		Filter(func(n int) bool { return n%2 == 0 }).
+		OnEach(func(n int) { seen = append(seen, n) }).
+		Take(2)
+	result, err := LazyMap(evens, strconv.Itoa).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "4"}, result, "wrong result")
+	assert.Equal(t, []int{2, 4}, seen, "elements after Take shouldn't be pulled")
+}
+
+func TestLazyStreamPullsOnlyWhatsNeeded(t *testing.T) {
+	// prepare
+	pulled := 0
+	s := FromSeq(func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	})
+
+	// call
+	first, ok := s.Drop(2).First()
+
+	// assert
+	assert.True(t, ok, "missing first element")
+	assert.Equal(t, 2, first, "wrong first element")
+	assert.Equal(t, 3, pulled, "wrong number of pulled elements")
+}
+
+func TestLazyStreamTerminalOps(t *testing.T) {
+	// prepare
+	var sum int
+	var ranged []int
+
+	// call
+	count := FromSeq(slices.Values([]int{1, 2, 3})).Count()
+	FromSeq(slices.Values([]int{1, 2, 3})).ForEach(func(n int) { sum += n })
+	for n := range FromSeq(slices.Values([]int{1, 2, 3})).Seq() {
+		ranged = append(ranged, n)
+	}
+	collected, err := FromSeq(slices.Values([]int{3, 1, 2})).Collect()
+	_, empty := FromSeq(slices.Values([]int{})).First()
+
+	// assert
+	assert.Equal(t, 3, count, "wrong count")
+	assert.Equal(t, 6, sum, "wrong sum")
+	assert.Equal(t, []int{1, 2, 3}, ranged, "wrong ranged elements")
+	require.NoError(t, err)
+	assert.Equal(t, 3, Max(collected), "wrong collected max")
+	assert.False(t, empty, "empty LazyStream shouldn't have a first element")
+}
+
+func TestLazyStreamLifecycle(t *testing.T) {
+	// prepare
+	consumed := FromSeq(slices.Values([]int{1, 2}))
+	closed := FromSeq(slices.Values([]int{1, 2}))
+
+	// call
+	consumed.Count()
+	err := closed.Close()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, StateConsumed, consumed.State(), "wrong state")
+	assert.Equal(t, StateClosed, closed.State(), "wrong state")
+	assert.PanicsWithError(t, "strm: stream already consumed: Filter called after Count", func() {
+		consumed.Filter(func(int) bool { return true })
+	})
+	assert.PanicsWithError(t, "strm: stream closed: Count called after Close", func() { closed.Count() })
+}
+
+func TestLazyMapConsumesInput(t *testing.T) {
+	// prepare
+	var closes atomic.Int32
+	input := closing(&closes, 1, 2, 3)
+
+	// call
+	mapped := LazyMap(input, func(n int) int { return n * 10 })
+
+	// assert
+	assert.Equal(t, StateConsumed, input.State(), "the input should be consumed")
+	assert.PanicsWithError(t, "strm: stream already consumed: Count called after LazyMap", func() { input.Count() })
+	assert.NoError(t, input.Close())
+	assert.Equal(t, int32(0), closes.Load(), "closing the input shouldn't release the source of the mapped stream")
+	assert.Equal(t, StateOpen, mapped.State(), "wrong state")
+	result, err := mapped.ToSlice()
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 20, 30}, result, "wrong mapping")
+	assert.Equal(t, int32(1), closes.Load(), "the source should be released once")
+}
+
+// returns a LazyStream of the given elements counting the releases of its source in [closes]
+func closing(closes *atomic.Int32, elems ...int) *LazyStream[int] {
+	return &LazyStream[int]{seq: slices.Values(elems), src: &lazySource{closer: func() error {
+		closes.Add(1)
+		return nil
+	}}}
+}
+
+func TestFromChan(t *testing.T) {
+	// prepare
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 1; i <= 5; i++ {
+			ch <- i
+		}
+	}()
+
+	// call
+	result, err := FromChan(ch).Filter(func(n int) bool { return n%2 == 1 }).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3, 5}, result, "wrong result")
+}
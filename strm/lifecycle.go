@@ -0,0 +1,134 @@
+package strm
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// State The lifecycle state of a Stream
+type State int
+
+const (
+	// StateOpen The Stream accepts any operation
+	StateOpen State = iota
+	// StateConsumed The Stream elements were consumed by a terminal operation: using it again panics
+	StateConsumed
+	// StateClosed The Stream was closed: using it again panics
+	StateClosed
+)
+
+var (
+	// ErrStreamConsumed The panic cause when using a Stream already consumed by a terminal operation
+	ErrStreamConsumed = errors.New("strm: stream already consumed")
+	// ErrStreamClosed The panic cause when using a closed Stream
+	ErrStreamClosed = errors.New("strm: stream closed")
+	// ErrConcurrentUse The panic cause when a Stream is used by several goroutines at once.
+	// Only detected by debug builds, with the strmdebug build tag
+	ErrConcurrentUse = errors.New("strm: stream used concurrently")
+)
+
+// String Returns the name of this State
+func (st State) String() string {
+	switch st {
+	case StateOpen:
+		return "open"
+	case StateConsumed:
+		return "consumed"
+	case StateClosed:
+		return "closed"
+	}
+	return fmt.Sprintf("State(%d)", int(st))
+}
+
+// State Returns the lifecycle state of this Stream.
+// A Stream is open until consumed by its first terminal operation (e.g. ToSlice, Count, Chunked or Map),
+// or until closed. Any further operation on it panics with ErrStreamConsumed or ErrStreamClosed.
+func (s *Stream[T]) State() State {
+	return s.lifecycle.state
+}
+
+// Close Closes this Stream, releasing its elements. Any further operation on it panics with ErrStreamClosed.
+// Closing a Stream more than once has no effect.
+func (s *Stream[T]) Close() error {
+	s.lifecycle.state = StateClosed
+	s.slice, s.filters = nil, nil
+	return nil
+}
+
+/*
+ * Internal Ops
+ */
+
+// the lifecycle tracking of a Stream
+type lifecycle struct {
+	state State
+	// the name of the terminal operation which consumed the Stream
+	consumedBy string
+	// the number of operations in progress, as operations may call other operations
+	depth int
+	// the id of the goroutine running the operations in progress, only tracked by debug builds
+	owner atomic.Int64
+}
+
+// an operation in progress on a Stream
+type operation struct {
+	name     string
+	terminal bool
+}
+
+// registers the start of the given operation, panicking if this Stream is no longer open, or if it's used by another
+// goroutine in debug builds. Meant to be paired with a deferred exit: defer s.exit(s.enter("Count", true))
+func (s *Stream[T]) enter(name string, terminal bool) operation {
+	if debugMode {
+		if gid := goroutineID(); !s.lifecycle.owner.CompareAndSwap(0, gid) && s.lifecycle.owner.Load() != gid {
+			panic(fmt.Errorf("%w: %s called while another goroutine is using it", ErrConcurrentUse, name))
+		}
+	}
+	if s.lifecycle.depth == 0 {
+		switch s.lifecycle.state {
+		case StateConsumed:
+			s.release()
+			panic(fmt.Errorf("%w: %s called after %s", ErrStreamConsumed, name, s.lifecycle.consumedBy))
+		case StateClosed:
+			s.release()
+			panic(fmt.Errorf("%w: %s called after Close", ErrStreamClosed, name))
+		}
+		s.recordStage(name, terminal)
+		s.startObservation()
+	}
+	s.lifecycle.depth++
+	return operation{name, terminal}
+}
+
+// registers the end of the given operation: once the outermost operation ends, a terminal one consumes this Stream.
+// Observed Streams are notified of the outermost operation end, even when it panics.
+func (s *Stream[T]) exit(op operation) {
+	s.lifecycle.depth--
+	if s.lifecycle.depth > 0 {
+		return
+	}
+	if stage := &s.plan.stages[len(s.plan.stages)-1]; !op.terminal && !stage.Lazy {
+		stage.Out = len(s.slice)
+	}
+	var panicked any
+	if s.observation.observer != nil {
+		// recover only works when called by the deferred function itself, the panic is raised again below
+		panicked = recover()
+		s.endObservation(panicked)
+	}
+	if op.terminal && s.lifecycle.state == StateOpen {
+		s.lifecycle.state, s.lifecycle.consumedBy = StateConsumed, op.name
+	}
+	s.release()
+	if panicked != nil {
+		panic(panicked)
+	}
+}
+
+// releases the ownership of this Stream by the current goroutine, only tracked by debug builds
+func (s *Stream[T]) release() {
+	if debugMode {
+		s.lifecycle.owner.Store(0)
+	}
+}
@@ -0,0 +1,60 @@
+//go:build strmdebug
+
+package strm
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentUseDetection(t *testing.T) {
+	// prepare
+	s := Range(1, 100).ToStrm()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var recovered any
+	var wg sync.WaitGroup
+
+	// call
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.ForEach(func(n int) {
+			if n == 1 {
+				close(started)
+				<-release
+			}
+		})
+	}()
+	<-started
+	func() {
+		defer func() { recovered = recover() }()
+		s.Count()
+	}()
+	close(release)
+	wg.Wait()
+
+	// assert
+	err, ok := recovered.(error)
+	assert.True(t, ok, "concurrent use wasn't detected")
+	assert.True(t, errors.Is(err, ErrConcurrentUse), "wrong panic cause")
+	assert.Equal(t, StateConsumed, s.State(), "wrong state")
+}
+
+func TestSequentialUseAcrossGoroutines(t *testing.T) {
+	// prepare
+	s := Of(1, 2, 3)
+	done := make(chan struct{})
+
+	// call
+	go func() {
+		defer close(done)
+		s.Filter(func(n int) bool { return n > 1 })
+	}()
+	<-done
+
+	// assert
+	assert.Equal(t, 2, s.Count(), "wrong count")
+}
@@ -0,0 +1,67 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStreamState(t *testing.T) {
+	// prepare
+	s := Of(1, 2, 3)
+
+	// call
+	open := s.Filter(func(n int) bool { return n > 1 }).Take(1).State()
+	s.Count()
+
+	// assert
+	assert.Equal(t, StateOpen, open, "wrong state")
+	assert.Equal(t, StateConsumed, s.State(), "wrong state")
+	assert.Equal(t, "consumed", s.State().String(), "wrong state name")
+}
+
+func TestReuseAfterTerminalOp(t *testing.T) {
+	// prepare
+	s := Of(1, 2, 3, 4, 5)
+	s.Chunked(2)
+
+	// assert
+	assert.PanicsWithError(t, "strm: stream already consumed: Count called after Chunked", func() { s.Count() })
+	assert.PanicsWithError(t, "strm: stream already consumed: Filter called after Chunked", func() {
+		s.Filter(func(int) bool { return true })
+	})
+	assert.PanicsWithError(t, "strm: stream already consumed: Map called after Chunked", func() {
+		Map(s, func(n int) int { return n })
+	})
+}
+
+func TestReuseAfterClose(t *testing.T) {
+	// prepare
+	s := Of(1, 2, 3)
+
+	// call
+	err := s.Close()
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, s.Close(), "closing twice should have no effect")
+	assert.Equal(t, StateClosed, s.State(), "wrong state")
+	assert.PanicsWithError(t, "strm: stream closed: ToSlice called after Close", func() { s.ToSlice() })
+}
+
+func TestNestedOpsConsumeOnce(t *testing.T) {
+	// prepare
+	s := RangeOf(1, 2, 3)
+	merged := Of(1)
+
+	// call
+	avg := s.Avg() // calls Sum internally
+	max := Max(Of(3, 1, 2))
+	self := Merge(merged, merged).ToSlice()
+
+	// assert
+	assert.Equal(t, 2, avg, "wrong average")
+	assert.Equal(t, 3, max, "wrong max")
+	assert.Equal(t, []int{1, 1}, self, "wrong value")
+	assert.Equal(t, StateConsumed, s.State(), "wrong state")
+	assert.Equal(t, StateConsumed, merged.State(), "wrong state")
+}
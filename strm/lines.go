@@ -0,0 +1,51 @@
+package strm
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// Lines Creates a new LazyStream of the text lines read from the given [reader], without their line endings.
+// Lines longer than bufio.MaxScanTokenSize stop the LazyStream with bufio.ErrTooLong, see FromScanner for larger
+// buffers. The given reader isn't closed.
+func Lines(reader io.Reader) *LazyStream[string] {
+	return FromScanner(bufio.NewScanner(reader))
+}
+
+// LinesFromFile Opens the file at the given [path] and creates a new LazyStream of its text lines, see Lines.
+// The file is closed once a terminal operation ends, or by LazyStream.Close.
+func LinesFromFile(path string) (*LazyStream[string], error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	s := Lines(file)
+	s.src.closer = file.Close
+	return s, nil
+}
+
+// FromScanner Creates a new LazyStream of the tokens read by the given [scanner], as strings.
+// Errors of the scanner stop the LazyStream and are returned by LazyStream.Err.
+func FromScanner(scanner *bufio.Scanner) *LazyStream[string] {
+	s := &LazyStream[string]{src: &lazySource{}}
+	s.seq = func(yield func(string) bool) {
+		for scanner.Scan() {
+			if !yield(scanner.Text()) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			s.src.fail(err)
+		}
+	}
+	return s
+}
+
+// Split Creates a new LazyStream of the tokens read from the given [reader] by the given [split] function,
+// e.g. bufio.ScanWords or bufio.ScanRunes. The given reader isn't closed.
+func Split(reader io.Reader, split bufio.SplitFunc) *LazyStream[string] {
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(split)
+	return FromScanner(scanner)
+}
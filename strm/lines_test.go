@@ -0,0 +1,98 @@
+package strm
+
+import (
+	"bufio"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	// prepare
+	reader := strings.NewReader("first\r\nsecond\n\nfourth")
+
+	// call
+	lines, err := Lines(reader).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "", "fourth"}, lines, "wrong lines")
+}
+
+func TestLinesFromFile(t *testing.T) {
+	// prepare
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("INFO start\nERROR disk full\nINFO retry\nERROR timeout\n"), 0o600))
+	s, err := LinesFromFile(path)
+	require.NoError(t, err)
+
+	// call
+	errorLine, ok := s.Filter(func(line string) bool { return strings.HasPrefix(line, "ERROR") }).First()
+
+	// assert
+	assert.True(t, ok, "missing error line")
+	assert.Equal(t, "ERROR disk full", errorLine, "wrong error line")
+	assert.NoError(t, s.Err())
+	assert.NoError(t, s.Close(), "closing twice shouldn't fail")
+}
+
+func TestLinesFromMissingFile(t *testing.T) {
+	// call
+	s, err := LinesFromFile(filepath.Join(t.TempDir(), "missing.log"))
+
+	// assert
+	assert.Nil(t, s)
+	assert.ErrorIs(t, err, os.ErrNotExist, "wrong error")
+}
+
+func TestLinesReaderError(t *testing.T) {
+	// prepare
+	readErr := errors.New("connection reset")
+	reader := io.MultiReader(strings.NewReader("first\nsecond\n"), &failingReader{readErr})
+
+	// call
+	s := Lines(reader)
+	count := s.Count()
+
+	// assert
+	assert.Equal(t, 2, count, "wrong count of lines before the error")
+	assert.ErrorIs(t, s.Err(), readErr, "wrong error")
+}
+
+func TestFromScannerTooLong(t *testing.T) {
+	// prepare
+	scanner := bufio.NewScanner(strings.NewReader(strings.Repeat("x", 100) + "\nshort\n"))
+	scanner.Buffer(make([]byte, 10), 50)
+
+	// call
+	lines, err := FromScanner(scanner).ToSlice()
+
+	// assert
+	assert.Empty(t, lines)
+	assert.ErrorIs(t, err, bufio.ErrTooLong, "wrong error")
+}
+
+func TestSplit(t *testing.T) {
+	// prepare
+	reader := strings.NewReader("the quick  brown\nfox")
+
+	// call
+	words, err := Split(reader, bufio.ScanWords).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"the", "quick", "brown", "fox"}, words, "wrong words")
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
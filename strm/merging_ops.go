@@ -1,15 +1,30 @@
 package strm
 
+import (
+	"container/heap"
+	"errors"
+	"golang.org/x/exp/constraints"
+	"iter"
+)
+
 // Plus copies the backing slices contents of both Streams into a new Stream
 func (s *Stream[T]) Plus(other *Stream[T]) *Stream[T] {
+	defer s.exit(s.enter("Plus", true))
+	defer other.exit(other.enter("Plus", true))
 	merged := make([]T, len(s.filteredSlice()), len(s.slice)+len(other.filteredSlice()))
 	copy(merged, s.slice)
-	return From(append(merged, other.slice...))
+	return derive(s, append(merged, other.slice...))
 }
 
 // Append appends the element of the given slice to this Stream
 func (s *Stream[T]) Append(elems []T) *Stream[T] {
+	defer s.exit(s.enter("Append", false))
 	s.filteredSlice()
+	if s.shared && len(elems) > 0 {
+		// clipping the capacity forces append to allocate, instead of writing past the caller's slice length
+		s.slice, s.shared = append(s.slice[:len(s.slice):len(s.slice)], elems...), false
+		return s
+	}
 	s.slice = append(s.slice, elems...)
 	return s
 }
@@ -20,11 +35,188 @@ func (s *Stream[T]) Append(elems []T) *Stream[T] {
 func Merge[T any](streams ...*Stream[T]) *Stream[T] {
 	lt := 0
 	for _, s := range streams {
+		defer s.exit(s.enter("Merge", true))
 		lt += len(s.filteredSlice())
 	}
 	merged := make([]T, 0, lt)
 	for _, s := range streams {
 		merged = append(merged, s.slice...)
 	}
-	return From(merged)
+	return newStream(merged, "merge")
+}
+
+// MergeSorted Merges the given [go-strm], each one sorted by the given [cmp] function, into a single sorted Stream
+// without sorting it again, e.g. for combining sorted shards. Equal elements keep the order of their [go-strm].
+// The k-way merge picks the next element among the heads of the given [go-strm] with a heap.
+func MergeSorted[T any](cmp func(a, b T) int, streams ...*Stream[T]) *Stream[T] {
+	return mergeSortedStreams("MergeSorted", cmp, streams)
+}
+
+// MergeSortedBy Merges the given [go-strm], each one sorted by the key produced by the given [keySelector],
+// into a single Stream sorted by that key, see MergeSorted
+func MergeSortedBy[T any, K constraints.Ordered](keySelector func(T) K, streams ...*Stream[T]) *Stream[T] {
+	return mergeSortedStreams("MergeSortedBy", byKey(keySelector), streams)
+}
+
+// LazyMergeSorted Returns a new LazyStream lazily merging the elements of the given LazyStreams, each one sorted by
+// the given [cmp] function, into sorted ones, e.g. for combining sorted log files or database cursors.
+// Equal elements keep the order of their LazyStreams. The first error of the given LazyStreams stops the returned one
+// and is returned by its Err. The given LazyStreams are consumed.
+func LazyMergeSorted[T any](cmp func(a, b T) int, streams ...*LazyStream[T]) *LazyStream[T] {
+	return lazyMergeSorted("LazyMergeSorted", cmp, streams)
+}
+
+// LazyMergeSortedBy Returns a new LazyStream lazily merging the elements of the given LazyStreams, each one sorted by
+// the key produced by the given [keySelector], into elements sorted by that key, see LazyMergeSorted
+func LazyMergeSortedBy[T any, K constraints.Ordered](keySelector func(T) K, streams ...*LazyStream[T]) *LazyStream[T] {
+	return lazyMergeSorted("LazyMergeSortedBy", byKey(keySelector), streams)
+}
+
+/*
+ * Internal Ops
+ */
+
+// merges the given sorted Streams with the given [cmp] function, as the operation of the given [name]
+func mergeSortedStreams[T any](name string, cmp func(a, b T) int, streams []*Stream[T]) *Stream[T] {
+	lt := 0
+	nexts := make([]func() (T, bool), 0, len(streams))
+	for _, s := range streams {
+		defer s.exit(s.enter(name, true))
+		lt += len(s.filteredSlice())
+		nexts = append(nexts, sliceNext(s.slice))
+	}
+	merged := make([]T, 0, lt)
+	mergeNexts(nexts, cmp, func(elem T) bool {
+		merged = append(merged, elem)
+		return true
+	})
+	return newStream(merged, "merge")
+}
+
+// lazily merges the given sorted LazyStreams with the given [cmp] function, as the operation of the given [name]
+func lazyMergeSorted[T any](name string, cmp func(a, b T) int, streams []*LazyStream[T]) *LazyStream[T] {
+	for _, s := range streams {
+		s.handOver(name)
+	}
+	started := false
+	merged := &LazyStream[T]{}
+	merged.src = &lazySource{closer: func() error {
+		if started {
+			return nil
+		}
+		var errs []error
+		for _, s := range streams {
+			errs = append(errs, s.src.release())
+		}
+		return errors.Join(errs...)
+	}}
+	merged.seq = func(yield func(T) bool) {
+		started = true
+		defer func() {
+			for _, s := range streams {
+				s.src.finish()
+				if err := s.src.error(); err != nil {
+					merged.src.fail(err)
+				}
+			}
+		}()
+		failed := false
+		sorted := make([]iter.Seq[T], len(streams))
+		for i, s := range streams {
+			sorted[i] = func(yield func(T) bool) {
+				for elem := range s.seq {
+					if !yield(elem) {
+						return
+					}
+				}
+				failed = failed || s.src.error() != nil
+			}
+		}
+		for elem := range mergeSorted(sorted, cmp) {
+			if failed || !yield(elem) {
+				return
+			}
+		}
+	}
+	return merged
+}
+
+// returns the k-way merge of the given sorted sequences, ties being broken by the order of the sequences
+func mergeSorted[T any](sorted []iter.Seq[T], cmp func(a, b T) int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		nexts := make([]func() (T, bool), len(sorted))
+		for i, seq := range sorted {
+			next, stop := iter.Pull(seq)
+			defer stop()
+			nexts[i] = next
+		}
+		mergeNexts(nexts, cmp, yield)
+	}
+}
+
+// yields the k-way merge of the sorted elements returned by the given [nexts] functions, until [yield] returns false
+func mergeNexts[T any](nexts []func() (T, bool), cmp func(a, b T) int, yield func(T) bool) {
+	h := &mergeHeap[T]{cmp: cmp}
+	for i, next := range nexts {
+		if elem, ok := next(); ok {
+			h.heads = append(h.heads, mergeHead[T]{elem, i, next})
+		}
+	}
+	heap.Init(h)
+	for h.Len() > 0 {
+		head := &h.heads[0]
+		if !yield(head.elem) {
+			return
+		}
+		if elem, ok := head.next(); ok {
+			head.elem = elem
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+}
+
+// returns a function returning the elements of the given slice one at a time
+func sliceNext[T any](slice []T) func() (T, bool) {
+	i := 0
+	return func() (elem T, ok bool) {
+		if i < len(slice) {
+			elem, ok = slice[i], true
+			i++
+		}
+		return
+	}
+}
+
+// the next element of each sequence being merged
+type mergeHead[T any] struct {
+	elem  T
+	index int // of the sequence
+	next  func() (T, bool)
+}
+
+// a min-heap of the next element of each sequence being merged, implementing heap.Interface
+type mergeHeap[T any] struct {
+	heads []mergeHead[T]
+	cmp   func(a, b T) int
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.heads) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	if c := h.cmp(h.heads[i].elem, h.heads[j].elem); c != 0 {
+		return c < 0
+	}
+	return h.heads[i].index < h.heads[j].index
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) { h.heads[i], h.heads[j] = h.heads[j], h.heads[i] }
+
+func (h *mergeHeap[T]) Push(x any) { h.heads = append(h.heads, x.(mergeHead[T])) }
+
+func (h *mergeHeap[T]) Pop() any {
+	last := h.heads[len(h.heads)-1]
+	h.heads = h.heads[:len(h.heads)-1]
+	return last
 }
@@ -1,7 +1,12 @@
 package strm
 
 import (
+	"cmp"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -39,3 +44,104 @@ func TestMerge(t *testing.T) {
 	assert.Equal(t, 5, len(slice1), "wrong length")
 	assert.Equal(t, 5, slice1[4], "wrong value")
 }
+
+func TestMergeSorted(t *testing.T) {
+	// prepare
+	filtered := Of(0, 5, 6).Filter(func(n int) bool { return n != 5 })
+
+	// call
+	merged := MergeSorted(cmp.Compare[int], Of(1, 4, 7), Of[int](), Of(2, 3, 8, 9), filtered)
+
+	// assert
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 6, 7, 8, 9}, merged.ToSlice(), "wrong merge")
+}
+
+func TestMergeSortedBy(t *testing.T) {
+	// prepare
+	type entry struct {
+		at    int
+		shard string
+	}
+	shard1 := Of(entry{1, "a"}, entry{3, "a"}, entry{3, "a"})
+	shard2 := Of(entry{2, "b"}, entry{3, "b"})
+
+	// call
+	merged := MergeSortedBy(func(e entry) int { return e.at }, shard1, shard2).ToSlice()
+
+	// assert
+	assert.Equal(t, []entry{{1, "a"}, {2, "b"}, {3, "a"}, {3, "a"}, {3, "b"}}, merged,
+		"ties should keep the order of the Streams")
+}
+
+func TestLazyMergeSorted(t *testing.T) {
+	// prepare
+	logs1 := Lines(strings.NewReader("2024-01-01 start\n2024-01-03 stop\n"))
+	logs2 := Lines(strings.NewReader("2024-01-02 retry\n2024-01-04 start\n"))
+
+	// call
+	merged, err := LazyMergeSorted(strings.Compare, logs1, logs2).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-01-01 start", "2024-01-02 retry", "2024-01-03 stop", "2024-01-04 start"}, merged,
+		"wrong merge")
+}
+
+func TestLazyMergeSortedBy(t *testing.T) {
+	// prepare
+	pulled := 0
+	s1 := FromSeq(slices.Values([]int{10, 30, 50})).OnEach(func(int) { pulled++ })
+	s2 := FromSeq(slices.Values([]int{20, 40, 60})).OnEach(func(int) { pulled++ })
+
+	// call
+	first, err := LazyMergeSortedBy(func(n int) int { return n / 10 }, s1, s2).Take(2).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 20}, first, "wrong merge")
+	assert.Equal(t, 3, pulled, "the elements should be pulled lazily")
+}
+
+func TestLazyMergeSortedError(t *testing.T) {
+	// prepare
+	failing := &LazyStream[int]{src: &lazySource{}}
+	failing.seq = func(yield func(int) bool) {
+		if yield(2) {
+			failing.src.fail(errors.New("cursor closed"))
+		}
+	}
+	closed := false
+	other := FromSeq(slices.Values([]int{1, 3, 5}))
+	other.src.closer = func() error {
+		closed = true
+		return nil
+	}
+
+	// call
+	merged, err := LazyMergeSorted(cmp.Compare[int], failing, other).ToSlice()
+
+	// assert
+	assert.EqualError(t, err, "cursor closed")
+	assert.Equal(t, []int{1, 2}, merged, "the merge should stop at the first error")
+	assert.True(t, closed, "all the LazyStreams should be released")
+}
+
+func TestLazyMergeSortedClose(t *testing.T) {
+	// prepare
+	closed := 0
+	streams := make([]*LazyStream[int], 2)
+	for i := range streams {
+		streams[i] = FromSeq(slices.Values([]int{i}))
+		streams[i].src.closer = func() error {
+			closed++
+			return nil
+		}
+	}
+
+	// call
+	err := LazyMergeSorted(cmp.Compare[int], streams...).Close()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, closed, "unread LazyStreams should be closed")
+}
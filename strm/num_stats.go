@@ -0,0 +1,140 @@
+package strm
+
+import (
+	"golang.org/x/exp/slices"
+	"math"
+)
+
+// Statistics The descriptive statistics of a NumStream, as computed by NumStream.SummaryStatistics
+type Statistics[N Number] struct {
+	Count int
+	Sum   N
+	Min   N
+	Max   N
+	Mean  float64
+	// Variance the sample variance (n-1 denominator), 0 for less than 2 elements
+	Variance float64
+	// StdDev the sample standard deviation, i.e. the square root of Variance
+	StdDev float64
+	// Skewness the population skewness, 0 when all elements are equal
+	Skewness float64
+	// Kurtosis the population excess kurtosis, 0 when all elements are equal
+	Kurtosis float64
+}
+
+// SummaryStatistics Returns the count, sum, min, max, mean, variance, standard deviation, skewness and kurtosis
+// of elements of this NumStream, computed in a single pass with Welford's online algorithm.
+// All statistics are 0 if this NumStream is empty
+func (s *NumStream[N]) SummaryStatistics() (stats Statistics[N]) {
+	defer s.exit(s.enter("SummaryStatistics", true))
+	var m2, m3, m4 float64
+
+	for _, elem := range s.filteredSlice() {
+		if stats.Count == 0 || elem < stats.Min {
+			stats.Min = elem
+		}
+		if stats.Count == 0 || elem > stats.Max {
+			stats.Max = elem
+		}
+		stats.Sum += elem
+
+		n1 := float64(stats.Count)
+		stats.Count++
+		n := float64(stats.Count)
+		delta := float64(elem) - stats.Mean
+		deltaN := delta / n
+		deltaN2 := deltaN * deltaN
+		term1 := delta * deltaN * n1
+		stats.Mean += deltaN
+		m4 += term1*deltaN2*(n*n-3*n+3) + 6*deltaN2*m2 - 4*deltaN*m3
+		m3 += term1*deltaN*(n-2) - 3*deltaN*m2
+		m2 += term1
+	}
+
+	n := float64(stats.Count)
+	if stats.Count > 1 {
+		stats.Variance = m2 / (n - 1)
+		stats.StdDev = math.Sqrt(stats.Variance)
+	}
+	if m2 > 0 {
+		stats.Skewness = math.Sqrt(n) * m3 / math.Pow(m2, 1.5)
+		stats.Kurtosis = n*m4/(m2*m2) - 3
+	}
+	return
+}
+
+// Median Returns the median of elements of this NumStream, or NaN if this NumStream is empty.
+// The order of the Stream elements is preserved
+func (s *NumStream[N]) Median() float64 {
+	defer s.exit(s.enter("Median", true))
+	return s.Percentile(50)
+}
+
+// Percentile Returns the [p]th percentile of elements of this NumStream, linearly interpolated between
+// the closest ranks. Returns NaN if this NumStream is empty or [p] is not within 0 and 100.
+// The order of the Stream elements is preserved
+func (s *NumStream[N]) Percentile(p float64) float64 {
+	defer s.exit(s.enter("Percentile", true))
+	if !(p >= 0 && p <= 100) { // NaN included
+		return math.NaN()
+	}
+	return percentile(s.sortedCopy(), p/100)
+}
+
+// Quantiles Returns the [n]-1 cut points dividing the elements of this NumStream into [n] intervals of equal
+// probability, e.g. the 3 quartiles for n=4, linearly interpolated between the closest ranks.
+// Returns nil if this NumStream is empty or [n] is lower than 2.
+// The order of the Stream elements is preserved
+func (s *NumStream[N]) Quantiles(n int) []float64 {
+	defer s.exit(s.enter("Quantiles", true))
+	if n < 2 || len(s.filteredSlice()) == 0 {
+		return nil
+	}
+	sorted := s.sortedCopy()
+	cutPoints := make([]float64, 0, n-1)
+	for i := 1; i < n; i++ {
+		cutPoints = append(cutPoints, percentile(sorted, float64(i)/float64(n)))
+	}
+	return cutPoints
+}
+
+// Mode Returns the most frequent element of this NumStream, the lowest one in case of a tie,
+// or 0 if this NumStream is empty. The order of the Stream elements is preserved
+func (s *NumStream[N]) Mode() (mode N) {
+	defer s.exit(s.enter("Mode", true))
+	sorted := s.sortedCopy()
+	bestCount := 0
+	for i, j := 0, 0; i < len(sorted); i = j {
+		// NaNs, sorted first, are grouped together as they're not equal to themselves
+		nan := sorted[i] != sorted[i]
+		for j = i + 1; j < len(sorted) && (sorted[j] == sorted[i] || nan && sorted[j] != sorted[j]); j++ {
+		}
+		if j-i > bestCount {
+			mode, bestCount = sorted[i], j-i
+		}
+	}
+	return
+}
+
+/*
+ * Internal Ops
+ */
+
+// returns a sorted copy of the filtered backing slice
+func (s *NumStream[N]) sortedCopy() []N {
+	sorted := make([]N, len(s.filteredSlice()))
+	copy(sorted, s.slice)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// returns the [q] quantile (within 0 and 1) of the given sorted slice, linearly interpolated between the closest ranks
+func percentile[N Number](sorted []N, q float64) float64 {
+	if len(sorted) == 0 || !(q >= 0 && q <= 1) {
+		return math.NaN()
+	}
+	rank := q * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return float64(sorted[lower]) + (rank-float64(lower))*(float64(sorted[upper])-float64(sorted[lower]))
+}
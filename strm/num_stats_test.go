@@ -0,0 +1,93 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestSummaryStatistics(t *testing.T) {
+	// call
+	stats := NumsOf(2.0, 4, 4, 4, 5, 5, 7, 9).SummaryStatistics()
+
+	// assert
+	assert.Equal(t, 8, stats.Count, "wrong count")
+	assert.Equal(t, 40.0, stats.Sum, "wrong sum")
+	assert.Equal(t, 2.0, stats.Min, "wrong min")
+	assert.Equal(t, 9.0, stats.Max, "wrong max")
+	assert.Equal(t, 5.0, stats.Mean, "wrong mean")
+	assert.InDelta(t, 32.0/7, stats.Variance, 1e-12, "wrong variance")
+	assert.InDelta(t, math.Sqrt(32.0/7), stats.StdDev, 1e-12, "wrong standard deviation")
+	assert.InDelta(t, 0.65625, stats.Skewness, 1e-12, "wrong skewness")
+	assert.InDelta(t, -0.21875, stats.Kurtosis, 1e-12, "wrong kurtosis")
+}
+
+func TestSummaryStatisticsEdgeCases(t *testing.T) {
+	// call
+	empty := NumsOf[int64]().SummaryStatistics()
+	single := NumsOf[int64](-3).SummaryStatistics()
+	ints := Range(1, 4).SummaryStatistics()
+
+	// assert
+	assert.Equal(t, Statistics[int64]{}, empty, "wrong statistics")
+	assert.Equal(t, Statistics[int64]{Count: 1, Sum: -3, Min: -3, Max: -3, Mean: -3}, single, "wrong statistics")
+	assert.Equal(t, 2.5, ints.Mean, "wrong mean")
+	assert.Equal(t, 0.0, ints.Skewness, "wrong skewness")
+}
+
+func TestMedianPercentile(t *testing.T) {
+	// prepare
+	latencies := []int{15, 20, 35, 40, 50}
+
+	// call
+	median := NumsFrom(latencies).Median()
+	evenMedian := RangeOf(4, 1, 3, 2).Median()
+	p40 := NumsFrom(latencies).Percentile(40)
+	p100 := RangeFrom(latencies).Percentile(100)
+
+	// assert
+	assert.Equal(t, 35.0, median, "wrong median")
+	assert.Equal(t, 2.5, evenMedian, "wrong median")
+	assert.Equal(t, 29.0, p40, "wrong percentile")
+	assert.Equal(t, 50.0, p100, "wrong percentile")
+	assert.True(t, math.IsNaN(NumsOf(1.0).Percentile(101)), "out of range percentile should be NaN")
+	assert.True(t, math.IsNaN(NumsOf(1.0, 2.0).Percentile(math.NaN())), "NaN percentile should be NaN")
+	assert.True(t, math.IsNaN(NumsOf[float64]().Median()), "empty median should be NaN")
+	assert.Equal(t, []int{15, 20, 35, 40, 50}, latencies, "elements order should be preserved")
+}
+
+func TestQuantiles(t *testing.T) {
+	// call
+	quartiles := NumRange(1, 9, 1).Quantiles(4)
+	none := NumsOf(1, 2).Quantiles(1)
+	intQuartiles := RangeStep(1, 9, 1).Quantiles(4)
+
+	// assert
+	assert.Equal(t, []float64{3, 5, 7}, quartiles, "wrong quartiles")
+	assert.Equal(t, []float64{3, 5, 7}, intQuartiles, "wrong quartiles")
+	assert.Nil(t, none, "wrong quantiles")
+}
+
+func TestMode(t *testing.T) {
+	// call
+	mode := NumsOf(3, 1, 3, 2, 1, 3).Mode()
+	tie := NumsOf(2.5, 1.5, 2.5, 1.5).Mode()
+	empty := NumsOf[int]().Mode()
+	intMode := RangeOf(4, 2, 4).Mode()
+
+	// assert
+	assert.Equal(t, 3, mode, "wrong mode")
+	assert.Equal(t, 4, intMode, "wrong mode")
+	assert.Equal(t, 1.5, tie, "wrong mode")
+	assert.Equal(t, 0, empty, "wrong mode")
+}
+
+func TestModeNaN(t *testing.T) {
+	// call
+	mode := NumsOf(1.0, math.NaN(), 2.0, 2.0).Mode()
+	nanMode := NumsOf(math.NaN(), 1.0, math.NaN()).Mode()
+
+	// assert
+	assert.Equal(t, 2.0, mode, "wrong mode")
+	assert.True(t, math.IsNaN(nanMode), "NaNs should be counted together")
+}
@@ -0,0 +1,279 @@
+package strm
+
+import (
+	"errors"
+	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
+	"math"
+	"reflect"
+)
+
+// ErrOverflow Returned by checked numeric operations whose result doesn't fit the Stream's numeric type
+var ErrOverflow = errors.New("strm: numeric overflow")
+
+// Number The constraint for the element type of a NumStream: any integer or floating-point type
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// NumStream A Stream of any integer or floating-point type
+type NumStream[N Number] struct {
+	*Stream[N]
+}
+
+// Float64Stream A NumStream of float64 elements
+type Float64Stream = NumStream[float64]
+
+// Int64Stream A NumStream of int64 elements
+type Int64Stream = NumStream[int64]
+
+/*
+ * Constructors
+ */
+
+// NumRange Returns a sequential ordered NumStream from [from] (inclusive) to [to] (inclusive)
+// by the given [step]. A negative [step] produces a descending NumStream, when [to] is lower than [from].
+// An empty NumStream is returned when [to] can't be reached from [from] with the given [step], or when any of them
+// is NaN or infinite. Float ranges include [to] when it's reached within the rounding errors of [step].
+func NumRange[N Number](from N, to N, step N) *NumStream[N] {
+	if step == 0 || (step > 0 && to < from) || (step < 0 && to > from) || !isFinite(from, to, step) {
+		return &NumStream[N]{newStream([]N{}, "range")}
+	}
+	if isFloatType[N]() {
+		return &NumStream[N]{newStream(floatRange(from, to, step), "range")}
+	}
+	// the distance is computed in uint64, as it may overflow N, e.g. from -100 to 100 in int8
+	distance, stride := uint64(to)-uint64(from), uint64(step)
+	if step < 0 {
+		distance, stride = uint64(from)-uint64(to), -stride
+	}
+	size := int(distance/stride) + 1
+	numSlice := make([]N, 0, size)
+	for i, elem := 0, from; i < size; i, elem = i+1, elem+step {
+		numSlice = append(numSlice, elem)
+	}
+	return &NumStream[N]{newStream(numSlice, "range")}
+}
+
+// NumsOf Creates a new NumStream backed by the given [elems]
+func NumsOf[N Number](elems ...N) *NumStream[N] {
+	return &NumStream[N]{Of(elems...)}
+}
+
+// NumsFrom Creates a new NumStream backed by the given [backingSlice]
+// the state of the given backingSlice will be preserved, see From
+func NumsFrom[N Number](backingSlice []N) *NumStream[N] {
+	return &NumStream[N]{From(backingSlice)}
+}
+
+// NumsCopyFrom Creates a new NumStream backed by a copy of the elements in the given [slice]
+// the state of the given slice will be preserved
+func NumsCopyFrom[N Number](slice []N) *NumStream[N] {
+	return &NumStream[N]{CopyFrom(slice)}
+}
+
+// AsNums Returns a NumStream enclosing the given Stream, e.g. for aggregating the results of a Map
+func AsNums[N Number](s *Stream[N]) *NumStream[N] {
+	return &NumStream[N]{s}
+}
+
+/*
+ * Main Ops
+ */
+
+// Sum Returns the sum of elements in this NumStream.
+// Integer sums may silently overflow, see SumChecked and KahanSum.
+func (s *NumStream[N]) Sum() (sum N) {
+	defer s.exit(s.enter("Sum", true))
+	for _, elem := range s.filteredSlice() {
+		sum += elem
+	}
+	return
+}
+
+// SumChecked Returns the sum of elements in this NumStream, or ErrOverflow if an integer sum overflows
+// or a floating-point sum of finite elements grows to infinity
+func (s *NumStream[N]) SumChecked() (sum N, err error) {
+	defer s.exit(s.enter("SumChecked", true))
+	float := isFloatType[N]()
+	for _, elem := range s.filteredSlice() {
+		next := sum + elem
+		if float {
+			if math.IsInf(float64(next), 0) && !math.IsInf(float64(elem), 0) && !math.IsInf(float64(sum), 0) {
+				return sum, s.failed(ErrOverflow)
+			}
+		} else if (elem > 0 && next < sum) || (elem < 0 && next > sum) {
+			return sum, s.failed(ErrOverflow)
+		}
+		sum = next
+	}
+	return sum, nil
+}
+
+// KahanSum Returns the sum of elements in this NumStream as a float64, using the Kahan-Babuska compensated
+// summation for reducing the floating-point rounding errors of large float Streams
+func (s *NumStream[N]) KahanSum() float64 {
+	defer s.exit(s.enter("KahanSum", true))
+	return kahanSum(s.filteredSlice())
+}
+
+// Avg Returns the arithmetic mean of elements of this NumStream, or 0 if this NumStream is empty
+func (s *NumStream[N]) Avg() float64 {
+	defer s.exit(s.enter("Avg", true))
+	if len(s.filteredSlice()) == 0 {
+		return 0
+	}
+	return kahanSum(s.slice) / float64(len(s.slice))
+}
+
+// Product Returns the product of elements in this NumStream, or 1 if this NumStream is empty
+func (s *NumStream[N]) Product() N {
+	defer s.exit(s.enter("Product", true))
+	product := N(1)
+	for _, elem := range s.filteredSlice() {
+		product *= elem
+	}
+	return product
+}
+
+// Min Returns the minimum element of this NumStream, or 0 if this NumStream is empty
+func (s *NumStream[N]) Min() N {
+	defer s.exit(s.enter("Min", true))
+	if len(s.filteredSlice()) == 0 {
+		return 0
+	}
+	return slices.Min(s.slice)
+}
+
+// Max Returns the maximum element of this NumStream, or 0 if this NumStream is empty
+func (s *NumStream[N]) Max() N {
+	defer s.exit(s.enter("Max", true))
+	if len(s.filteredSlice()) == 0 {
+		return 0
+	}
+	return slices.Max(s.slice)
+}
+
+// Sorted sorts the NumStream in increasing order.
+func (s *NumStream[N]) Sorted() *NumStream[N] {
+	defer s.exit(s.enter("Sorted", false))
+	s.filteredSlice()
+	s.own()
+	slices.Sort(s.slice)
+	return s
+}
+
+/*
+ * Adapter Ops
+ */
+
+// Filter see Stream.Filter
+func (s *NumStream[N]) Filter(p predicate[N]) *NumStream[N] {
+	s.Stream.Filter(p)
+	return s
+}
+
+// ApplyOnEach see Stream.ApplyOnEach
+func (s *NumStream[N]) ApplyOnEach(action func(N) N) *NumStream[N] {
+	s.Stream.ApplyOnEach(action)
+	return s
+}
+
+// OnEach see Stream.OnEach
+func (s *NumStream[N]) OnEach(action func(N)) *NumStream[N] {
+	s.Stream.OnEach(action)
+	return s
+}
+
+// Distinct see Stream.Distinct
+func (s *NumStream[N]) Distinct() *NumStream[N] {
+	s.Stream.Distinct()
+	return s
+}
+
+// Reversed see Stream.Reversed
+func (s *NumStream[N]) Reversed() *NumStream[N] {
+	s.Stream.Reversed()
+	return s
+}
+
+// Take see Stream.Take
+func (s *NumStream[N]) Take(n int) *NumStream[N] {
+	s.Stream.Take(n)
+	return s
+}
+
+// Drop see Stream.Drop
+func (s *NumStream[N]) Drop(n int) *NumStream[N] {
+	s.Stream.Drop(n)
+	return s
+}
+
+// Observe see Stream.Observe
+func (s *NumStream[N]) Observe(observer Observer) *NumStream[N] {
+	s.Stream.Observe(observer)
+	return s
+}
+
+// ToStrm Returns the enclosed *Stream[N] from this NumStream
+func (s *NumStream[N]) ToStrm() *Stream[N] {
+	return s.Stream
+}
+
+/*
+ * Internal Ops
+ */
+
+// returns true if the given generic type is a floating-point type
+func isFloatType[N Number]() bool {
+	kind := reflect.TypeOf((*N)(nil)).Elem().Kind()
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// returns true if none of the given numbers is NaN or infinite
+func isFinite[N Number](nums ...N) bool {
+	for _, num := range nums {
+		if f := float64(num); math.IsNaN(f) || math.IsInf(f, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// returns the float range from [from] to [to] by [step], computed in float64 and including [to] when the number
+// of steps reaching it is within rounding errors
+func floatRange[N Number](from N, to N, step N) []N {
+	start, end, stride := float64(from), float64(to), float64(step)
+	steps := (end - start) / stride
+	tolerance := 1e-9
+	if reflect.TypeOf(from).Kind() == reflect.Float32 {
+		tolerance = 1e-6
+	}
+	size := int(math.Floor(steps+tolerance*max(1, steps))) + 1
+	numSlice := make([]N, 0, size)
+	for i := 0; i < size; i++ {
+		// multiplying instead of accumulating avoids drifting float ranges
+		elem := start + float64(i)*stride
+		if (stride > 0 && elem > end) || (stride < 0 && elem < end) {
+			elem = end
+		}
+		numSlice = append(numSlice, N(elem))
+	}
+	return numSlice
+}
+
+// sums the given slice as float64 with the Kahan-Babuska (Neumaier) compensated summation
+func kahanSum[N Number](slice []N) float64 {
+	var sum, compensation float64
+	for _, elem := range slice {
+		v := float64(elem)
+		t := sum + v
+		if math.Abs(sum) >= math.Abs(v) {
+			compensation += (sum - t) + v
+		} else {
+			compensation += (v - t) + sum
+		}
+		sum = t
+	}
+	return sum + compensation
+}
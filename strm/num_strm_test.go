@@ -0,0 +1,134 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestNumRange(t *testing.T) {
+	// call
+	floats := NumRange(0.0, 1.0, 0.25).ToSlice()
+	descending := NumRange[int64](10, 0, -5).ToSlice()
+	empty := NumRange(0.0, 1.0, 0).ToSlice()
+
+	// assert
+	assert.Equal(t, []float64{0, 0.25, 0.5, 0.75, 1}, floats, "wrong range")
+	assert.Equal(t, []int64{10, 5, 0}, descending, "wrong range")
+	assert.Equal(t, 0, len(empty), "wrong length")
+}
+
+func TestNumRangeNarrowTypes(t *testing.T) {
+	// call
+	int8s := NumRange[int8](-100, 100, 1).ToSlice()
+	int8Steps := NumRange[int8](-100, 100, 50).ToSlice()
+	descending := NumRange[int8](100, -100, -50).ToSlice()
+	uint8s := NumRange[uint8](0, 255, 1).ToSlice()
+
+	// assert
+	assert.Equal(t, 201, len(int8s), "wrong length")
+	assert.Equal(t, int8(100), int8s[200], "wrong last element")
+	assert.Equal(t, []int8{-100, -50, 0, 50, 100}, int8Steps, "wrong range")
+	assert.Equal(t, []int8{100, 50, 0, -50, -100}, descending, "wrong range")
+	assert.Equal(t, 256, len(uint8s), "wrong length")
+	assert.Equal(t, uint8(255), uint8s[255], "wrong last element")
+}
+
+func TestNumRangeFloatBounds(t *testing.T) {
+	// call
+	tenths := NumRange(0, 0.3, 0.1).ToSlice()
+	descending := NumRange(0.3, 0, -0.1).ToSlice()
+	float32s := NumRange[float32](0, 1, 0.1).ToSlice()
+
+	// assert
+	assert.Equal(t, []float64{0, 0.1, 0.2, 0.3}, tenths, "the end should be included")
+	assert.Equal(t, 4, len(descending), "wrong length")
+	assert.Equal(t, 0.0, descending[3], "wrong last element")
+	assert.Equal(t, 11, len(float32s), "wrong length")
+	assert.Equal(t, float32(1), float32s[10], "wrong last element")
+	assert.Empty(t, NumRange(0, math.NaN(), 1).ToSlice(), "NaN should be rejected")
+	assert.Empty(t, NumRange(0, math.Inf(1), 1).ToSlice(), "Inf should be rejected")
+	assert.Empty(t, NumRange(0, 1, math.Inf(1)).ToSlice(), "Inf should be rejected")
+}
+
+func TestNumSum(t *testing.T) {
+	// call
+	sum := NumsOf[int64](1, 2, 3).Sum()
+	floatSum := NumsOf(0.5, 1.5).Sum()
+
+	// assert
+	assert.Equal(t, int64(6), sum, "wrong sum")
+	assert.Equal(t, 2.0, floatSum, "wrong sum")
+}
+
+func TestNumSumChecked(t *testing.T) {
+	// call
+	sum, err := NumsOf[int8](100, 20, 7).SumChecked()
+	_, overflowErr := NumsOf[int8](100, 20, 8).SumChecked()
+	_, underflowErr := NumsOf[int8](-100, -29).SumChecked()
+	_, uintErr := NumsOf[uint8](200, 56).SumChecked()
+	_, floatErr := NumsOf(math.MaxFloat64, math.MaxFloat64).SumChecked()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, int8(127), sum, "wrong sum")
+	assert.ErrorIs(t, overflowErr, ErrOverflow)
+	assert.ErrorIs(t, underflowErr, ErrOverflow)
+	assert.ErrorIs(t, uintErr, ErrOverflow)
+	assert.ErrorIs(t, floatErr, ErrOverflow)
+}
+
+func TestNumKahanSum(t *testing.T) {
+	// prepare
+	var values []float64
+	for i := 0; i < 10; i++ {
+		values = append(values, 0.1)
+	}
+
+	// call
+	naive := NumsCopyFrom(values).Sum()
+	kahan := NumsFrom(values).KahanSum()
+
+	// assert
+	assert.NotEqual(t, 1.0, naive, "naive sum should accumulate rounding errors")
+	assert.Equal(t, 1.0, kahan, "wrong sum")
+}
+
+func TestNumAvg(t *testing.T) {
+	// call
+	avg := NumsOf(1, 2).Avg()
+	floatAvg := NumsOf(1.5, 2.5, 3.5).Avg()
+	empty := NumsOf[float64]().Avg()
+
+	// assert
+	assert.Equal(t, 1.5, avg, "wrong average")
+	assert.Equal(t, 2.5, floatAvg, "wrong average")
+	assert.Equal(t, 0.0, empty, "wrong average")
+}
+
+func TestNumMinMaxProduct(t *testing.T) {
+	// call
+	min := NumsOf(3.5, -1.0, 2.0).Min()
+	max := NumsOf[int64](3, -1, 2).Max()
+	product := NumsOf[int64](2, 3, 4).Product()
+	emptyMin := NumsOf[int64]().Min()
+
+	// assert
+	assert.Equal(t, -1.0, min, "wrong min")
+	assert.Equal(t, int64(3), max, "wrong max")
+	assert.Equal(t, int64(24), product, "wrong product")
+	assert.Equal(t, int64(0), emptyMin, "wrong min")
+}
+
+func TestNumSorted(t *testing.T) {
+	// call
+	sorted := AsNums(Map(Of("3.5", "1", "2"), func(s string) float64 { f, _ := strconv.ParseFloat(s, 64); return f })).
+		Filter(func(f float64) bool { return f > 1 }).
+		Sorted().
+		ToSlice()
+
+	// assert
+	assert.Equal(t, []float64{2, 3.5}, sorted, "wrong sorting")
+}
@@ -0,0 +1,287 @@
+package strm
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// Observer Receives an event at the end of each operation applied to an observed Stream, see Stream.Observe.
+// OnStage is called synchronously by the goroutine running the operation, so it should return quickly.
+type Observer interface {
+	OnStage(event StageEvent)
+}
+
+// StageEvent The metrics of an operation applied to an observed Stream
+type StageEvent struct {
+	// Stage the recorded operation, with its element counts and the goroutines it launched, see Stream.Explain
+	Stage
+	// Index the position of the operation in the Stream's Stages, starting at 0
+	Index int
+	Start time.Time
+	// Duration the time spent by the operation, including the pending filters it applied. Lazy operations, like
+	// Filter, are notified once their filter is applied, with the time spent by its predicate
+	Duration time.Duration
+	// Allocs the number of heap objects allocated while the operation ran, by any goroutine of the process
+	Allocs uint64
+	// Err the error returned by the operation, or the panic it raised
+	Err error
+}
+
+// ObserverFunc Adapts an ordinary callback function to an Observer
+type ObserverFunc func(event StageEvent)
+
+// OnStage Calls f(event)
+func (f ObserverFunc) OnStage(event StageEvent) {
+	f(event)
+}
+
+// Observe Sets the Observer notified at the end of every following operation applied to this Stream,
+// and to the Streams derived from it, e.g. by Map. A nil [observer] stops observing this Stream.
+func (s *Stream[T]) Observe(observer Observer) *Stream[T] {
+	s.observation.observer = observer
+	return s
+}
+
+/*
+ * Adapters
+ */
+
+// ExpvarObserver An Observer publishing the aggregated metrics of each operation name as an expvar.Map:
+// the number of calls, the elements in and out, the total nanoseconds, allocations and goroutines, and the errors
+type ExpvarObserver struct {
+	vars *expvar.Map
+}
+
+// NewExpvarObserver Creates an ExpvarObserver publishing its metrics under the given expvar [name].
+// Observers created with the same [name] share the same published metrics.
+func NewExpvarObserver(name string) *ExpvarObserver {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	if vars, ok := expvar.Get(name).(*expvar.Map); ok {
+		return &ExpvarObserver{vars}
+	}
+	return &ExpvarObserver{expvar.NewMap(name)}
+}
+
+// OnStage Adds the given event to the metrics of its operation name
+func (o *ExpvarObserver) OnStage(event StageEvent) {
+	stage, ok := o.vars.Get(event.Name).(*expvar.Map)
+	if !ok {
+		expvarMu.Lock()
+		if stage, ok = o.vars.Get(event.Name).(*expvar.Map); !ok {
+			stage = new(expvar.Map).Init()
+			o.vars.Set(event.Name, stage)
+		}
+		expvarMu.Unlock()
+	}
+	stage.Add("calls", 1)
+	stage.Add("in", int64(event.In))
+	if event.Out >= 0 {
+		stage.Add("out", int64(event.Out))
+	}
+	stage.Add("nanos", event.Duration.Nanoseconds())
+	stage.Add("allocs", int64(event.Allocs))
+	stage.Add("goroutines", int64(event.Goroutines))
+	if event.Err != nil {
+		stage.Add("errors", 1)
+	}
+}
+
+// SpanData A finished span describing an operation of an observed Stream, shaped after the OpenTelemetry spans:
+// it carries the "strm.stage.*" attributes and an error status, for a SpanExporter to convert and export
+type SpanData struct {
+	Name       string
+	StartTime  time.Time
+	EndTime    time.Time
+	Attributes map[string]any
+	Err        error
+}
+
+// SpanExporter Exports the spans of a SpanObserver, e.g. to an OpenTelemetry tracer or collector
+type SpanExporter interface {
+	ExportSpans(ctx context.Context, spans []SpanData) error
+}
+
+// SpanObserver An Observer exporting a span per operation of an observed Stream
+type SpanObserver struct {
+	ctx      context.Context
+	exporter SpanExporter
+}
+
+// NewSpanObserver Creates a SpanObserver exporting its spans to the given [exporter] with the given [ctx].
+// Export errors are ignored: observing a Stream never fails its operations.
+func NewSpanObserver(ctx context.Context, exporter SpanExporter) *SpanObserver {
+	return &SpanObserver{ctx, exporter}
+}
+
+// OnStage Exports a span for the given event, named "strm.<operation>"
+func (o *SpanObserver) OnStage(event StageEvent) {
+	attributes := map[string]any{
+		"strm.stage.index":    event.Index,
+		"strm.stage.in":       event.In,
+		"strm.stage.terminal": event.Terminal,
+		"strm.stage.allocs":   event.Allocs,
+	}
+	if event.Out >= 0 {
+		attributes["strm.stage.out"] = event.Out
+	}
+	if event.Goroutines > 0 {
+		attributes["strm.stage.goroutines"] = event.Goroutines
+	}
+	span := SpanData{
+		Name:       "strm." + event.Name,
+		StartTime:  event.Start,
+		EndTime:    event.Start.Add(event.Duration),
+		Attributes: attributes,
+		Err:        event.Err,
+	}
+	_ = o.exporter.ExportSpans(o.ctx, []SpanData{span})
+}
+
+// InMemoryExporter A SpanExporter keeping the exported spans in memory, e.g. for testing
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// ExportSpans Appends the given [spans] to the ones kept by this InMemoryExporter
+func (e *InMemoryExporter) ExportSpans(_ context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+// Spans Returns a copy of the spans exported so far
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Reset Discards the spans exported so far
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+/*
+ * Internal Ops
+ */
+
+// guards the creation of published expvar Maps
+var expvarMu sync.Mutex
+
+const heapAllocsMetric = "/gc/heap/allocs:objects"
+
+// the Observer of a Stream and the measures of its operation in progress
+type observation struct {
+	observer Observer
+	start    time.Time
+	allocs   uint64
+	err      error
+	// the measures of the pending filters, notified once applied
+	filters []*filterObservation
+}
+
+// the measures of the predicate of a lazy operation
+type filterObservation struct {
+	// the index of the operation in the stages of the Stream, excluding the ones of the Streams it was derived from
+	index int
+	// the time of the first call of the predicate
+	start    time.Time
+	duration time.Duration
+}
+
+// starts measuring the outermost operation, if this Stream is observed
+func (s *Stream[T]) startObservation() {
+	if s.observation.observer == nil {
+		return
+	}
+	s.observation.start, s.observation.allocs, s.observation.err = time.Now(), heapAllocs(), nil
+}
+
+// notifies the Observer of this Stream with the measures of the ended outermost operation
+func (s *Stream[T]) endObservation(panicked any) {
+	if s.observation.observer == nil {
+		return
+	}
+	err := s.observation.err
+	stage := s.plan.stages[len(s.plan.stages)-1]
+	if stage.Lazy && panicked == nil {
+		// notified once its filter is applied, see notifyFilters
+		return
+	}
+	if panicked != nil {
+		if err, _ = panicked.(error); err == nil {
+			err = fmt.Errorf("strm: panic: %v", panicked)
+		}
+	}
+	s.observation.observer.OnStage(StageEvent{
+		Stage:    stage,
+		Index:    s.plan.len() - 1,
+		Start:    s.observation.start,
+		Duration: time.Since(s.observation.start),
+		Allocs:   heapAllocs() - s.observation.allocs,
+		Err:      err,
+	})
+}
+
+// returns the given predicate of the lazy operation in progress, timing its calls if this Stream is observed
+func (s *Stream[T]) observedFilter(p predicate[T]) predicate[T] {
+	if s.observation.observer == nil {
+		return p
+	}
+	f := &filterObservation{index: len(s.plan.stages) - 1}
+	s.observation.filters = append(s.observation.filters, f)
+	return func(elem T) bool {
+		start := time.Now()
+		if f.start.IsZero() {
+			f.start = start
+		}
+		matched := p(elem)
+		f.duration += time.Since(start)
+		return matched
+	}
+}
+
+// notifies the Observer of this Stream of the lazy operations whose filters were applied
+func (s *Stream[T]) notifyFilters() {
+	filters := s.observation.filters
+	s.observation.filters = nil
+	if s.observation.observer == nil {
+		return
+	}
+	for _, f := range filters {
+		if f.start.IsZero() {
+			f.start = time.Now()
+		}
+		s.observation.observer.OnStage(StageEvent{
+			Stage:    s.plan.stages[f.index],
+			Index:    s.plan.base + f.index,
+			Start:    f.start,
+			Duration: f.duration,
+		})
+	}
+}
+
+// records the given error returned by the operation in progress, returning it
+func (s *Stream[T]) failed(err error) error {
+	s.observation.err = err
+	return err
+}
+
+// returns the cumulative number of heap objects allocated by the process
+func heapAllocs() uint64 {
+	sample := []metrics.Sample{{Name: heapAllocsMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
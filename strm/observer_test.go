@@ -0,0 +1,161 @@
+package strm
+
+import (
+	"context"
+	"expvar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestObserve(t *testing.T) {
+	// prepare
+	var events []StageEvent
+	s := Of(1, 2, 3, 4, 5, 6).Observe(ObserverFunc(func(e StageEvent) { events = append(events, e) }))
+
+	// call
+	doubled := Map(s.Filter(func(n int) bool { return n%2 == 0 }), func(n int) int { return n * 2 })
+	result := doubled.Take(2).ToSlice()
+
+	// assert
+	assert.Equal(t, []int{4, 8}, result, "wrong result")
+	require.Len(t, events, 4, "wrong number of events")
+	assert.Equal(t, []string{"Filter", "Map", "Take", "ToSlice"}, eventNames(events), "wrong events")
+	assert.Equal(t, Stage{Name: "Map", Terminal: true, In: 6, Out: 3}, events[1].Stage, "wrong map event")
+	assert.Equal(t, 2, events[2].Index, "wrong event index")
+	for _, e := range events {
+		assert.False(t, e.Start.IsZero(), "missing start time")
+		assert.GreaterOrEqual(t, e.Duration.Nanoseconds(), int64(0), "wrong duration")
+		assert.NoError(t, e.Err, "unexpected error")
+	}
+}
+
+func TestObserveFilterDuration(t *testing.T) {
+	// prepare
+	var events []StageEvent
+	s := Of(1, 2, 3).Observe(ObserverFunc(func(e StageEvent) { events = append(events, e) }))
+
+	// call
+	count := s.Filter(func(n int) bool {
+		time.Sleep(time.Millisecond) // a slow predicate
+		return n > 1
+	}).Count()
+
+	// assert
+	assert.Equal(t, 2, count, "wrong count")
+	require.Len(t, events, 2, "wrong number of events")
+	assert.Equal(t, []string{"Filter", "Count"}, eventNames(events), "wrong events")
+	assert.GreaterOrEqual(t, events[0].Duration, 3*time.Millisecond, "the time of the predicate should be reported")
+	assert.GreaterOrEqual(t, events[1].Duration, events[0].Duration, "the applying operation should include it")
+}
+
+func TestObserveParallelStage(t *testing.T) {
+	// prepare
+	var events []StageEvent
+	s := FromMutable(make([]int, 100)).Observe(ObserverFunc(func(e StageEvent) { events = append(events, e) }))
+
+	// call
+	// large objects are counted once allocated, unlike small ones which may be counted in batches
+	PMap(s, func(n int) []byte { return make([]byte, 64<<10) })
+
+	// assert
+	require.Len(t, events, 1, "wrong number of events")
+	assert.Positive(t, events[0].Goroutines, "wrong goroutines")
+	assert.Positive(t, events[0].Allocs, "wrong allocs")
+}
+
+func TestObserveErrors(t *testing.T) {
+	// prepare
+	var events []StageEvent
+	observer := ObserverFunc(func(e StageEvent) { events = append(events, e) })
+	panicking := Of(1, 2).Observe(observer)
+
+	// call
+	_, err := NumsOf(int8(100), int8(100)).Observe(observer).SumChecked()
+	assert.PanicsWithValue(t, "boom", func() { panicking.ForEach(func(int) { panic("boom") }) })
+
+	// assert
+	require.Len(t, events, 2, "wrong number of events")
+	assert.ErrorIs(t, err, ErrOverflow, "wrong error")
+	assert.ErrorIs(t, events[0].Err, ErrOverflow, "wrong event error")
+	assert.EqualError(t, events[1].Err, "strm: panic: boom", "wrong event panic")
+	assert.Equal(t, StateConsumed, panicking.State(), "wrong state")
+}
+
+func TestObservePipeline(t *testing.T) {
+	// prepare
+	var names []string
+	p := NewPipeline[int]().
+		Observe(ObserverFunc(func(e StageEvent) { names = append(names, e.Name) })).
+		Filter(func(n int) bool { return n > 1 }).
+		Distinct()
+
+	// call
+	result := p.Run([]int{1, 2, 2, 3})
+
+	// assert
+	assert.Equal(t, []int{2, 3}, result, "wrong result")
+	assert.Equal(t, []string{"Filter", "Distinct", "ToSlice"}, names, "wrong events")
+}
+
+func TestExpvarObserver(t *testing.T) {
+	// prepare
+	observer := NewExpvarObserver("strm_test_stages")
+	observer.vars.Init() // clears the metrics of previous runs, e.g. with -count
+
+	// call
+	Of(1, 2, 3).Observe(observer).Filter(func(n int) bool { return n > 1 }).Count()
+	Of(4, 5).Observe(NewExpvarObserver("strm_test_stages")).Count()
+
+	// assert
+	vars := expvar.Get("strm_test_stages").(*expvar.Map)
+	count := vars.Get("Count").(*expvar.Map)
+	assert.Equal(t, "2", count.Get("calls").String(), "wrong calls")
+	assert.Equal(t, "5", count.Get("in").String(), "wrong elements in")
+	assert.Equal(t, "4", count.Get("out").String(), "wrong elements out")
+	assert.Nil(t, vars.Get("Filter").(*expvar.Map).Get("out"), "lazy stages have no elements out")
+}
+
+func TestSpanObserver(t *testing.T) {
+	// prepare
+	exporter := &InMemoryExporter{}
+	observer := NewSpanObserver(context.Background(), exporter)
+
+	// call
+	_, err := Of(1, 2, 3).Observe(observer).Take(2).TryDistinct()
+	spans := exporter.Spans()
+	exporter.Reset()
+
+	// assert
+	require.NoError(t, err)
+	require.Len(t, spans, 2, "wrong number of spans")
+	assert.Equal(t, "strm.Take", spans[0].Name, "wrong span name")
+	assert.Equal(t, "strm.TryDistinct", spans[1].Name, "wrong span name")
+	assert.Equal(t, 3, spans[0].Attributes["strm.stage.in"], "wrong in attribute")
+	assert.Equal(t, 2, spans[0].Attributes["strm.stage.out"], "wrong out attribute")
+	assert.Equal(t, 1, spans[1].Attributes["strm.stage.index"], "wrong index attribute")
+	assert.False(t, spans[0].EndTime.Before(spans[0].StartTime), "wrong span times")
+	assert.Empty(t, exporter.Spans(), "spans should be reset")
+}
+
+func TestSpanObserverError(t *testing.T) {
+	// prepare
+	exporter := &InMemoryExporter{}
+	records := []hashedRecord{{id: -1}}
+
+	// call
+	_, err := Of(records...).Observe(NewSpanObserver(context.Background(), exporter)).TryDistinct()
+
+	// assert
+	require.Error(t, err)
+	require.Len(t, exporter.Spans(), 1, "wrong number of spans")
+	assert.ErrorIs(t, exporter.Spans()[0].Err, err, "wrong span error")
+}
+
+func eventNames(events []StageEvent) (names []string) {
+	for _, e := range events {
+		names = append(names, e.Name)
+	}
+	return
+}
@@ -1,12 +1,14 @@
 package strm
 
 import (
+	"fmt"
 	"golang.org/x/exp/constraints"
 )
 
 // OnEach executes the given [action] on each element and returns the unchanged Stream afterwards.
 // used mainly for debugging purposes.
 func (s *Stream[T]) OnEach(f func(T)) *Stream[T] {
+	defer s.exit(s.enter("OnEach", false))
 	for _, elem := range s.filteredSlice() {
 		f(elem)
 	}
@@ -16,7 +18,10 @@ func (s *Stream[T]) OnEach(f func(T)) *Stream[T] {
 // ApplyOnEach Applies the given [action] on each element of the backing slice returns the Stream afterwards.
 // Similar to Map, but the returning type of the given [action] but math this Stream's type
 func (s *Stream[T]) ApplyOnEach(action func(T) T) *Stream[T] {
-	for i, elem := range s.filteredSlice() {
+	defer s.exit(s.enter("ApplyOnEach", false))
+	s.filteredSlice()
+	s.own()
+	for i, elem := range s.slice {
 		s.slice[i] = action(elem)
 	}
 	return s
@@ -25,6 +30,7 @@ func (s *Stream[T]) ApplyOnEach(action func(T) T) *Stream[T] {
 // Take Returns this Stream containing first [n] elements.
 // [n] must be positive.
 func (s *Stream[T]) Take(n int) *Stream[T] {
+	defer s.exit(s.enter("Take", false))
 	if len(s.filteredSlice()) <= n {
 		return s
 	}
@@ -33,7 +39,7 @@ func (s *Stream[T]) Take(n int) *Stream[T] {
 		s.slice = nil
 		return s
 	}
-	for i := n; i < len(s.slice); i++ {
+	for i := n; i < len(s.slice) && !s.shared; i++ {
 		s.slice[i] = *new(T) // garbage collection: sets the zero value for T
 	}
 	s.slice = s.slice[:n]
@@ -43,6 +49,7 @@ func (s *Stream[T]) Take(n int) *Stream[T] {
 // Drop Returns this Stream containing all elements except first [n] elements.
 // [n] must be positive.
 func (s *Stream[T]) Drop(n int) *Stream[T] {
+	defer s.exit(s.enter("Drop", false))
 	if n == 0 {
 		return s
 	}
@@ -51,7 +58,7 @@ func (s *Stream[T]) Drop(n int) *Stream[T] {
 		s.slice = nil
 		return s
 	}
-	for i := 0; i < n; i++ {
+	for i := 0; i < n && !s.shared; i++ {
 		s.slice[i] = *new(T) // garbage collection: sets the zero value for T
 	}
 	s.slice = s.slice[n:]
@@ -60,6 +67,7 @@ func (s *Stream[T]) Drop(n int) *Stream[T] {
 
 // Max Returns the largest element of the Ordered constrained Stream.
 func Max[O constraints.Ordered](s *Stream[O]) (max O) {
+	defer s.exit(s.enter("Max", true))
 	max = s.First()
 	for _, elem := range s.slice {
 		if elem > max {
@@ -71,6 +79,7 @@ func Max[O constraints.Ordered](s *Stream[O]) (max O) {
 
 // Min Returns the smallest element of the Ordered constrained Stream.
 func Min[O constraints.Ordered](s *Stream[O]) (min O) {
+	defer s.exit(s.enter("Min", true))
 	min = s.First()
 	for _, elem := range s.slice {
 		if elem < min {
@@ -82,6 +91,7 @@ func Min[O constraints.Ordered](s *Stream[O]) (min O) {
 
 // Sum Returns the sum of all elements in this Ordered constrained Stream.
 func Sum[O constraints.Ordered](s *Stream[O]) (sum O) {
+	defer s.exit(s.enter("Sum", true))
 	for _, elem := range s.filteredSlice() {
 		sum += elem
 	}
@@ -90,7 +100,10 @@ func Sum[O constraints.Ordered](s *Stream[O]) (sum O) {
 
 // Reversed reverses the elements order of this Stream
 func (s *Stream[T]) Reversed() *Stream[T] {
-	for i := len(s.filteredSlice())/2 - 1; i >= 0; i-- {
+	defer s.exit(s.enter("Reversed", false))
+	s.filteredSlice()
+	s.own()
+	for i := len(s.slice)/2 - 1; i >= 0; i-- {
 		opp := len(s.slice) - 1 - i
 		s.slice[i], (s.slice)[opp] = (s.slice)[opp], (s.slice)[i]
 	}
@@ -100,7 +113,9 @@ func (s *Stream[T]) Reversed() *Stream[T] {
 // Distinct In-place deduplication of the backing slice, guided with a map.
 // Internally uses a custom hash for comparing non-comparable types
 func (s *Stream[T]) Distinct() *Stream[T] {
+	defer s.exit(s.enter("Distinct", false))
 	keys := make(map[any]struct{}, len(s.filteredSlice()))
+	s.own()
 	j := 0
 
 	for i := 0; i < len(s.slice); i++ {
@@ -118,10 +133,197 @@ func (s *Stream[T]) Distinct() *Stream[T] {
 	return s
 }
 
+// TryDistinct Same as Distinct, but reports an error when an element can't be hashed
+// instead of falling back to the element pointer, which would keep its duplicates.
+// The Stream is left unchanged when an error is returned.
+func (s *Stream[T]) TryDistinct() (*Stream[T], error) {
+	defer s.exit(s.enter("TryDistinct", false))
+	hashKeys := make([]any, len(s.filteredSlice()))
+	for i := range s.slice {
+		hashKey, err := s.hashKey(i)
+		if err != nil {
+			return s, s.failed(fmt.Errorf("strm: hashing element at index %d: %w", i, err))
+		}
+		hashKeys[i] = hashKey
+	}
+
+	keys := make(map[any]struct{}, len(s.slice))
+	i := 0
+	s.retain([]predicate[T]{func(T) bool {
+		hashKey := hashKeys[i]
+		i++
+		if _, ok := keys[hashKey]; ok {
+			return false
+		}
+		keys[hashKey] = struct{}{}
+		return true
+	}})
+	return s, nil
+}
+
+// DistinctWith In-place deduplication of the backing slice using the given [equal] and [hash] functions.
+// Elements with the same hash are compared with [equal], so hash collisions never drop distinct elements.
+func (s *Stream[T]) DistinctWith(equal func(a, b T) bool, hash func(T) uint64) *Stream[T] {
+	defer s.exit(s.enter("DistinctWith", false))
+	buckets := make(map[uint64][]T, len(s.filteredSlice()))
+
+	s.retain([]predicate[T]{func(elem T) bool {
+		hashKey := hash(elem)
+		for _, seen := range buckets[hashKey] {
+			if equal(seen, elem) {
+				return false
+			}
+		}
+		buckets[hashKey] = append(buckets[hashKey], elem)
+		return true
+	}})
+	return s
+}
+
+// DistinctBy In-place deduplication of the backing slice, keeping only the first element
+// for each key produced by the given [keySelector]
+func DistinctBy[T any, K comparable](s *Stream[T], keySelector func(T) K) *Stream[T] {
+	defer s.exit(s.enter("DistinctBy", false))
+	keys := make(map[K]struct{}, len(s.filteredSlice()))
+
+	s.retain([]predicate[T]{func(elem T) bool {
+		key := keySelector(elem)
+		if _, ok := keys[key]; ok {
+			return false
+		}
+		keys[key] = struct{}{}
+		return true
+	}})
+	return s
+}
+
+// DistinctUntilChanged In-place removal of consecutive duplicated elements, keeping the first one of each run.
+// Non-adjacent duplicates are preserved. Internally uses the same hashing as Distinct for non-comparable types
+func (s *Stream[T]) DistinctUntilChanged() *Stream[T] {
+	defer s.exit(s.enter("DistinctUntilChanged", false))
+	var prevKey any
+	j := 0
+	s.filteredSlice()
+	s.own()
+
+	for i := 0; i < len(s.slice); i++ {
+		hashKey := s.calculateHash(i)
+		if i > 0 && hashKey == prevKey {
+			continue
+		}
+		prevKey = hashKey
+		s.slice[j], j = s.slice[i], j+1
+	}
+	for i := j; i < len(s.slice); i++ {
+		s.slice[i] = *new(T) // garbage-collection: sets the zero value for T
+	}
+	s.slice = s.slice[:j]
+	return s
+}
+
+// DistinctUntilChangedBy In-place removal of consecutive elements producing the same key with the given
+// [keySelector], keeping the first one of each run.
+func DistinctUntilChangedBy[T any, K comparable](s *Stream[T], keySelector func(T) K) *Stream[T] {
+	defer s.exit(s.enter("DistinctUntilChangedBy", false))
+	var prevKey K
+	first := true
+
+	s.retain([]predicate[T]{func(elem T) bool {
+		key := keySelector(elem)
+		if !first && key == prevKey {
+			return false
+		}
+		prevKey, first = key, false
+		return true
+	}})
+	return s
+}
+
+// Run A value repeated [Count] consecutive times, as produced by RunLengthEncode
+type Run[T any] struct {
+	Value T
+	Count int
+}
+
+// RunLengthEncode Returns a new Stream of Runs, one per each sequence of consecutive equal elements in the given Stream.
+// Internally uses the same hashing as Distinct for non-comparable types
+func RunLengthEncode[T any](s *Stream[T]) *Stream[Run[T]] {
+	defer s.exit(s.enter("RunLengthEncode", true))
+	var runs []Run[T]
+	var prevKey any
+
+	for i := 0; i < len(s.filteredSlice()); i++ {
+		hashKey := s.calculateHash(i)
+		if i > 0 && hashKey == prevKey {
+			runs[len(runs)-1].Count++
+			continue
+		}
+		prevKey = hashKey
+		runs = append(runs, Run[T]{Value: s.slice[i], Count: 1})
+	}
+	return derive(s, runs)
+}
+
+// RunLengthDecode Returns a new Stream containing the value of each Run repeated by its count.
+// The reverse operation of RunLengthEncode
+func RunLengthDecode[T any](runs *Stream[Run[T]]) *Stream[T] {
+	defer runs.exit(runs.enter("RunLengthDecode", true))
+	size := 0
+	for _, run := range runs.filteredSlice() {
+		size += run.Count
+	}
+	decoded := make([]T, 0, size)
+	for _, run := range runs.slice {
+		for i := 0; i < run.Count; i++ {
+			decoded = append(decoded, run.Value)
+		}
+	}
+	return derive(runs, decoded)
+}
+
+// ChunkBy Splits this Stream into several slices of consecutive elements sharing the same key produced by the given
+// [keySelector]. Unlike GroupBy, elements with equal keys which aren't adjacent end up in different slices.
+func ChunkBy[T any, K comparable](s *Stream[T], keySelector func(T) K) [][]T {
+	defer s.exit(s.enter("ChunkBy", true))
+	var prevKey K
+	return splitRuns(s.filteredSlice(), func(i int) bool {
+		key := keySelector(s.slice[i])
+		changed := i > 0 && key != prevKey
+		prevKey = key
+		return changed
+	})
+}
+
+// SplitWhen Splits this Stream into several slices of consecutive elements, starting a new slice between each pair
+// of adjacent elements matching the given predicate [p]
+func (s *Stream[T]) SplitWhen(p func(prev T, next T) bool) [][]T {
+	defer s.exit(s.enter("SplitWhen", true))
+	return splitRuns(s.filteredSlice(), func(i int) bool {
+		return i > 0 && p(s.slice[i-1], s.slice[i])
+	})
+}
+
+// splits the given slice before each index matching the given [boundary], without copying its elements
+func splitRuns[T any](slice []T, boundary func(i int) bool) [][]T {
+	var runs [][]T
+	start := 0
+
+	for i := range slice {
+		if boundary(i) {
+			runs, start = append(runs, slice[start:i:i]), i
+		}
+	}
+	if start < len(slice) {
+		runs = append(runs, slice[start:])
+	}
+	return runs
+}
+
 // Chunked Splits this Stream into several slices each not exceeding the given [size]
 // The last list may have fewer elements than the given [size].
 //	 size: the nr. of elems to take in each slice, must be >0 and can be greater than the nr of elems in this stream
 func (s *Stream[T]) Chunked(batchSize int) [][]T {
+	defer s.exit(s.enter("Chunked", true))
 	batches := make([][]T, 0, (len(s.filteredSlice())+batchSize-1)/batchSize)
 
 	for batchSize < len(s.slice) {
@@ -137,6 +339,7 @@ func (s *Stream[T]) Chunked(batchSize int) [][]T {
 // 	 step: the number of elements to move the window forward by on each step
 //	 partialWindows: controls whether to keep partial windows in the end if any, false by default
 func (s *Stream[T]) Windowed(size int, step int, partialWindows ...bool) [][]T {
+	defer s.exit(s.enter("Windowed", true))
 	// returns the input slice as the first element
 	if len(s.filteredSlice()) <= size {
 		return [][]T{s.slice}
@@ -1,7 +1,10 @@
 package strm
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
 )
 
@@ -296,3 +299,131 @@ func TestDropNothing(t *testing.T) {
 	// assert
 	assert.Equal(t, 4, len(got), "wrong length")
 }
+
+type hashedRecord struct {
+	id      int
+	payload []string
+}
+
+func (r hashedRecord) Hash() (uint64, error) {
+	if r.id < 0 {
+		return 0, errors.New("negative id")
+	}
+	return uint64(r.id), nil
+}
+
+func TestDistinctBy(t *testing.T) {
+	// prepare
+	type Record struct {
+		id   int
+		tags []string
+	}
+
+	// call
+	got := DistinctBy(
+		Of(Record{1, []string{"a"}}, Record{2, nil}, Record{1, []string{"b"}}),
+		func(r Record) int { return r.id },
+	).ToSlice()
+
+	// assert
+	assert.Equal(t, 2, len(got), "wrong length")
+	assert.Equal(t, []string{"a"}, got[0].tags, "wrong value")
+	assert.Equal(t, 2, got[1].id, "wrong value")
+}
+
+func TestDistinctWith(t *testing.T) {
+	// call
+	got := Of("a", "A", "b", "ab", "AB").
+		DistinctWith(
+			strings.EqualFold,
+			func(s string) uint64 { return uint64(len(s)) }, // colliding hash on purpose
+		).
+		ToSlice()
+
+	// assert
+	assert.Equal(t, []string{"a", "b", "ab"}, got, "wrong value")
+}
+
+func TestDistinctHasher(t *testing.T) {
+	// call
+	got := Of(hashedRecord{1, []string{"a"}}, hashedRecord{1, []string{"b"}}, hashedRecord{2, nil}).
+		Distinct().
+		ToSlice()
+
+	// assert
+	assert.Equal(t, 2, len(got), "wrong length")
+	assert.Equal(t, []string{"a"}, got[0].payload, "wrong value")
+}
+
+func TestTryDistinct(t *testing.T) {
+	// call
+	got, err := Of(hashedRecord{1, nil}, hashedRecord{1, nil}, hashedRecord{2, nil}).TryDistinct()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Count(), "wrong length")
+}
+
+func TestTryDistinctError(t *testing.T) {
+	// prepare
+	initSlice := []hashedRecord{{1, nil}, {1, nil}, {-1, nil}}
+
+	// call
+	got, err := From(initSlice).TryDistinct()
+
+	// assert
+	assert.ErrorContains(t, err, "negative id")
+	assert.Equal(t, 3, got.Count(), "stream should be left unchanged")
+}
+
+func TestDistinctUntilChanged(t *testing.T) {
+	// call
+	got := Of(1, 1, 2, 2, 2, 1, 3, 3).DistinctUntilChanged().ToSlice()
+	gotSlices := Of([]int{1}, []int{1}, []int{2}, []int{1}).DistinctUntilChanged().ToSlice()
+
+	// assert
+	assert.Equal(t, []int{1, 2, 1, 3}, got, "wrong value")
+	assert.Equal(t, [][]int{{1}, {2}, {1}}, gotSlices, "wrong value")
+}
+
+func TestDistinctUntilChangedBy(t *testing.T) {
+	// call
+	got := DistinctUntilChangedBy(
+		Of(Person{"Tim", 30}, Person{"Tom", 30}, Person{"Bil", 40}, Person{"Ann", 30}),
+		func(p Person) int { return p.age },
+	).ToSlice()
+
+	// assert
+	assert.Equal(t, []Person{{"Tim", 30}, {"Bil", 40}, {"Ann", 30}}, got, "wrong value")
+}
+
+func TestRunLengthEncodeDecode(t *testing.T) {
+	// call
+	runs := RunLengthEncode(Of("a", "a", "a", "b", "a", "a")).ToSlice()
+	decoded := RunLengthDecode(From(runs)).ToSlice()
+	empty := RunLengthEncode(Of[string]()).ToSlice()
+
+	// assert
+	assert.Equal(t, []Run[string]{{"a", 3}, {"b", 1}, {"a", 2}}, runs, "wrong runs")
+	assert.Equal(t, []string{"a", "a", "a", "b", "a", "a"}, decoded, "wrong decoded value")
+	assert.Equal(t, 0, len(empty), "wrong length")
+}
+
+func TestChunkBy(t *testing.T) {
+	// call
+	chunks := ChunkBy(Of(1, 3, 2, 4, 6, 5, 8), func(n int) bool { return n%2 == 0 })
+	empty := ChunkBy(Of[int](), func(n int) int { return n })
+
+	// assert
+	assert.Equal(t, [][]int{{1, 3}, {2, 4, 6}, {5}, {8}}, chunks, "wrong chunks")
+	assert.Equal(t, 0, len(empty), "wrong length")
+}
+
+func TestSplitWhen(t *testing.T) {
+	// call
+	chunks := Of(1, 2, 3, 7, 8, 12).SplitWhen(func(prev, next int) bool { return next-prev > 1 })
+
+	// assert
+	assert.Equal(t, [][]int{{1, 2, 3}, {7, 8}, {12}}, chunks, "wrong chunks")
+	assert.Equal(t, 3, cap(chunks[0]), "chunks mustn't overlap")
+}
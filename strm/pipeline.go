@@ -0,0 +1,91 @@
+package strm
+
+// Pipeline A reusable chain of operations turning a Stream of IN elements into a Stream of OUT elements.
+// Its stages are recorded independently of any data, and applied to as many Streams or slices as needed.
+// Pipelines are immutable: adding a stage returns a new Pipeline, so they can be shared, extended and run concurrently.
+type Pipeline[IN any, OUT any] struct {
+	apply func(*Stream[IN]) *Stream[OUT]
+}
+
+/*
+ * Constructors
+ */
+
+// NewPipeline Creates an empty Pipeline, passing its input elements through unchanged
+func NewPipeline[T any]() *Pipeline[T, T] {
+	return &Pipeline[T, T]{apply: func(s *Stream[T]) *Stream[T] { return s }}
+}
+
+// Compose Returns a new Pipeline applying the [first] Pipeline and then the [second] one to its results
+func Compose[IN any, MID any, OUT any](first *Pipeline[IN, MID], second *Pipeline[MID, OUT]) *Pipeline[IN, OUT] {
+	return &Pipeline[IN, OUT]{apply: func(s *Stream[IN]) *Stream[OUT] { return second.apply(first.apply(s)) }}
+}
+
+/*
+ * Stages
+ */
+
+// MapStage Returns a new Pipeline adding a Map stage to the given Pipeline, see Map
+func MapStage[IN any, MID any, OUT any](p *Pipeline[IN, MID], f mapper[MID, OUT]) *Pipeline[IN, OUT] {
+	return &Pipeline[IN, OUT]{apply: func(s *Stream[IN]) *Stream[OUT] { return Map(p.apply(s), f) }}
+}
+
+// FlatMapStage Returns a new Pipeline adding a FlatMap stage to the given Pipeline, see FlatMap
+func FlatMapStage[IN any, MID any, OUT any](p *Pipeline[IN, MID], f mapper[MID, *Stream[OUT]]) *Pipeline[IN, OUT] {
+	return &Pipeline[IN, OUT]{apply: func(s *Stream[IN]) *Stream[OUT] { return FlatMap(p.apply(s), f) }}
+}
+
+// Filter Returns a new Pipeline adding a Filter stage to this Pipeline, see Stream.Filter
+func (p *Pipeline[IN, OUT]) Filter(pr predicate[OUT]) *Pipeline[IN, OUT] {
+	return p.then(func(s *Stream[OUT]) *Stream[OUT] { return s.Filter(pr) })
+}
+
+// Distinct Returns a new Pipeline adding a Distinct stage to this Pipeline, see Stream.Distinct
+func (p *Pipeline[IN, OUT]) Distinct() *Pipeline[IN, OUT] {
+	return p.then((*Stream[OUT]).Distinct)
+}
+
+// Take Returns a new Pipeline adding a Take stage to this Pipeline, see Stream.Take
+func (p *Pipeline[IN, OUT]) Take(n int) *Pipeline[IN, OUT] {
+	return p.then(func(s *Stream[OUT]) *Stream[OUT] { return s.Take(n) })
+}
+
+// Drop Returns a new Pipeline adding a Drop stage to this Pipeline, see Stream.Drop
+func (p *Pipeline[IN, OUT]) Drop(n int) *Pipeline[IN, OUT] {
+	return p.then(func(s *Stream[OUT]) *Stream[OUT] { return s.Drop(n) })
+}
+
+// ApplyOnEach Returns a new Pipeline adding an ApplyOnEach stage to this Pipeline, see Stream.ApplyOnEach
+func (p *Pipeline[IN, OUT]) ApplyOnEach(action func(OUT) OUT) *Pipeline[IN, OUT] {
+	return p.then(func(s *Stream[OUT]) *Stream[OUT] { return s.ApplyOnEach(action) })
+}
+
+// Observe Returns a new Pipeline notifying the given [observer] of each operation applied by this Pipeline,
+// and by the stages added after it, to every Stream it's applied to, see Stream.Observe
+func (p *Pipeline[IN, OUT]) Observe(observer Observer) *Pipeline[IN, OUT] {
+	return &Pipeline[IN, OUT]{apply: func(s *Stream[IN]) *Stream[OUT] { return p.apply(s.Observe(observer)) }}
+}
+
+/*
+ * Running
+ */
+
+// Apply Applies this Pipeline to the given Stream, returning the resulting Stream
+func (p *Pipeline[IN, OUT]) Apply(s *Stream[IN]) *Stream[OUT] {
+	return p.apply(s)
+}
+
+// Run Applies this Pipeline to a Stream built From the given [slice], returning the resulting slice.
+// The state of the given slice is preserved.
+func (p *Pipeline[IN, OUT]) Run(slice []IN) []OUT {
+	return p.apply(From(slice)).ToSlice()
+}
+
+/*
+ * Internal Ops
+ */
+
+// returns a new Pipeline applying the given same-type operation after this Pipeline's stages
+func (p *Pipeline[IN, OUT]) then(op func(*Stream[OUT]) *Stream[OUT]) *Pipeline[IN, OUT] {
+	return &Pipeline[IN, OUT]{apply: func(s *Stream[IN]) *Stream[OUT] { return op(p.apply(s)) }}
+}
@@ -0,0 +1,94 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPipelineRun(t *testing.T) {
+	// prepare
+	pipeline := MapStage(
+		NewPipeline[string]().Filter(func(s string) bool { return s != "" }).Distinct(),
+		func(s string) int { n, _ := strconv.Atoi(s); return n },
+	).Filter(func(n int) bool { return n > 1 }).Take(2)
+	batch := []string{"1", "", "2", "2", "3", "4"}
+
+	// call
+	first := pipeline.Run(batch)
+	second := pipeline.Run([]string{"5", "5", "6"})
+	empty := pipeline.Run(nil)
+
+	// assert
+	assert.Equal(t, []int{2, 3}, first, "wrong value")
+	assert.Equal(t, []int{5, 6}, second, "wrong value")
+	assert.Equal(t, 0, len(empty), "wrong length")
+	assert.Equal(t, []string{"1", "", "2", "2", "3", "4"}, batch, "input slice was modified")
+}
+
+func TestPipelineApply(t *testing.T) {
+	// prepare
+	pipeline := FlatMapStage(
+		NewPipeline[string]().ApplyOnEach(strings.ToUpper),
+		func(s string) *Stream[string] { return From(strings.Split(s, ",")) },
+	).Drop(1)
+
+	// call
+	got := pipeline.Apply(Of("a,b", "c")).JoinToString("")
+
+	// assert
+	assert.Equal(t, "BC", got, "wrong value")
+}
+
+func TestPipelineImmutability(t *testing.T) {
+	// prepare
+	base := NewPipeline[int]().Filter(func(n int) bool { return n > 1 })
+	even := base.Filter(func(n int) bool { return n%2 == 0 })
+
+	// call
+	fromBase := base.Run([]int{1, 2, 3, 4})
+	fromEven := even.Run([]int{1, 2, 3, 4})
+
+	// assert
+	assert.Equal(t, []int{2, 3, 4}, fromBase, "extending a Pipeline shouldn't change it")
+	assert.Equal(t, []int{2, 4}, fromEven, "wrong value")
+}
+
+func TestCompose(t *testing.T) {
+	// prepare
+	parse := MapStage(NewPipeline[string](), func(s string) int { n, _ := strconv.Atoi(s); return n })
+	format := MapStage(
+		NewPipeline[int]().Filter(func(n int) bool { return n%2 == 0 }),
+		func(n int) string { return "#" + strconv.Itoa(n) },
+	)
+
+	// call
+	got := Compose(parse, format).Run([]string{"1", "2", "3", "4"})
+
+	// assert
+	assert.Equal(t, []string{"#2", "#4"}, got, "wrong value")
+}
+
+func TestPipelineConcurrentRuns(t *testing.T) {
+	// prepare
+	pipeline := NewPipeline[int]().Distinct().Filter(func(n int) bool { return n%2 == 0 })
+	results := make([][]int, 50)
+	var wg sync.WaitGroup
+
+	// call
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = pipeline.Run([]int{i, i, i + 1, i + 1})
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	for i, result := range results {
+		assert.Equal(t, []int{i + i%2}, result, "wrong value")
+	}
+}
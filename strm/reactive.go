@@ -0,0 +1,400 @@
+package strm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// Publisher A push-based source of elements, sending them to its Subscribers only as fast as they request them
+type Publisher[T any] interface {
+	// Subscribe Starts sending elements to the given [subscriber], once it requests them through the Subscription
+	// passed to its OnSubscribe
+	Subscribe(subscriber Subscriber[T])
+}
+
+// Subscriber Receives the elements of a Publisher. Its methods are called serially: OnSubscribe first, then OnNext
+// for at most as many elements as requested, and finally either OnError or OnComplete unless the Subscription
+// was cancelled.
+type Subscriber[T any] interface {
+	OnSubscribe(subscription Subscription)
+	OnNext(elem T)
+	OnError(err error)
+	OnComplete()
+}
+
+// Subscription The link between a Publisher and one of its Subscribers, signalling the demand of the Subscriber
+type Subscription interface {
+	// Request Adds [n] elements to the demand of the Subscriber, non-positive values being ignored.
+	// It may be called from any goroutine, including from OnNext.
+	Request(n int)
+	// Cancel Stops sending elements to the Subscriber, it may still receive the ones already being sent
+	Cancel()
+}
+
+/*
+ * Constructors
+ */
+
+// NewPublisher Creates a new Publisher running the given [produce] function on a new goroutine for each Subscriber:
+// the given emit function sends an element to the Subscriber, blocking until the Subscriber requested it, and returns
+// false once the Subscription is cancelled, when the given context is cancelled too. The Subscriber completes once
+// [produce] returns, with the error it returned if any.
+func NewPublisher[T any](produce func(ctx context.Context, emit func(T) bool) error) Publisher[T] {
+	return funcPublisher[T](produce)
+}
+
+// PublisherFromChan Creates a new Publisher of the elements received from the given channel [ch], completing once
+// it's closed. Elements are only received as requested by the Subscriber, holding at most one at a time, so that
+// the producer of [ch] blocks while the Subscriber is saturated.
+func PublisherFromChan[T any](ch <-chan T) Publisher[T] {
+	return NewPublisher(func(ctx context.Context, emit func(T) bool) error {
+		for {
+			select {
+			case elem, ok := <-ch:
+				if !ok || !emit(elem) {
+					return nil
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+}
+
+// ToPublisher Returns a Publisher of the elements of this Stream, sending them to each of its Subscribers
+func (s *Stream[T]) ToPublisher() Publisher[T] {
+	defer s.exit(s.enter("ToPublisher", true))
+	elems := s.filteredSlice()
+	return NewPublisher(func(_ context.Context, emit func(T) bool) error {
+		for _, elem := range elems {
+			if !emit(elem) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// ToPublisher Returns a Publisher pulling the elements of this LazyStream as its Subscriber requests them, completing
+// with the error of the source if any. Only its first Subscriber receives the elements, the following ones
+// receive ErrStreamConsumed. The source is released once the first Subscriber completes or cancels: a Publisher no one
+// subscribes to holds it until this LazyStream is closed.
+func (s *LazyStream[T]) ToPublisher() Publisher[T] {
+	s.use("ToPublisher", true)
+	var subscribed atomic.Bool
+	return NewPublisher(func(_ context.Context, emit func(T) bool) error {
+		if subscribed.Swap(true) {
+			return fmt.Errorf("%w: Subscribe called after ToPublisher", ErrStreamConsumed)
+		}
+		defer s.src.finish()
+		for elem := range s.seq {
+			if !emit(elem) {
+				return nil
+			}
+		}
+		return s.src.error()
+	})
+}
+
+// ToStream Returns a new LazyStream subscribing to the given Publisher once pulled, requesting up to [bufferSize]
+// elements ahead of the ones pulled: a slow consumer makes the Publisher wait. A non-positive [bufferSize] requests
+// one element at a time. The error the Publisher completes with is returned by LazyStream.Err, and the Subscription
+// is cancelled when a terminal operation stops early, e.g. Take.
+func ToStream[T any](p Publisher[T], bufferSize int) *LazyStream[T] {
+	bufferSize = max(bufferSize, 1)
+	s := &LazyStream[T]{src: &lazySource{}}
+	s.seq = func(yield func(T) bool) {
+		subscriber := &bufferingSubscriber[T]{bufferSize: bufferSize, elems: make(chan T, bufferSize)}
+		p.Subscribe(subscriber)
+		for elem := range subscriber.elems {
+			if !yield(elem) {
+				subscriber.subscription.Cancel()
+				return
+			}
+			subscriber.subscription.Request(1)
+		}
+		if subscriber.err != nil {
+			s.src.fail(subscriber.err)
+		}
+	}
+	return s
+}
+
+/*
+ * Stages
+ */
+
+// MapPublisher Returns a new Publisher sending the elements of the given Publisher transformed by the given [mapper]
+func MapPublisher[T any, R any](p Publisher[T], mapper func(T) R) Publisher[R] {
+	return &stage[T, R]{p, func(down Subscriber[R]) Subscriber[T] { return &mapSubscriber[T, R]{down, mapper} }}
+}
+
+// FilterPublisher Returns a new Publisher sending the elements of the given Publisher matching the given [predicate].
+// Each element filtered out is requested again from the given Publisher, so that the demand is preserved.
+func FilterPublisher[T any](p Publisher[T], predicate func(T) bool) Publisher[T] {
+	return &stage[T, T]{p, func(down Subscriber[T]) Subscriber[T] { return &filterSubscriber[T]{down: down, predicate: predicate} }}
+}
+
+// FlatMapPublisher Returns a new Publisher sending the elements of the Streams returned by the given [mapper] for each
+// element of the given Publisher. Elements are requested one at a time from the given Publisher, once the elements of
+// the previous Stream have all been requested.
+func FlatMapPublisher[T any, R any](p Publisher[T], mapper func(T) *Stream[R]) Publisher[R] {
+	return &stage[T, R]{p, func(down Subscriber[R]) Subscriber[T] { return &flatMapSubscriber[T, R]{down: down, mapper: mapper} }}
+}
+
+/*
+ * Internal Ops
+ */
+
+// a Publisher running a produce function, see NewPublisher
+type funcPublisher[T any] func(ctx context.Context, emit func(T) bool) error
+
+func (produce funcPublisher[T]) Subscribe(subscriber Subscriber[T]) {
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription := &demandSubscription{cancel: cancel}
+	subscription.cond = sync.NewCond(&subscription.mu)
+	subscriber.OnSubscribe(subscription)
+	go func() {
+		defer cancel()
+		err := produce(ctx, func(elem T) bool {
+			if !subscription.acquire() {
+				return false
+			}
+			subscriber.OnNext(elem)
+			return true
+		})
+		if subscription.cancelled() {
+			return
+		}
+		if err != nil {
+			subscriber.OnError(err)
+		} else {
+			subscriber.OnComplete()
+		}
+	}()
+}
+
+// a Subscription counting the demand of its Subscriber
+type demandSubscription struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	demand   int
+	canceled bool
+	cancel   context.CancelFunc
+}
+
+func (s *demandSubscription) Request(n int) {
+	if n <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.demand = min(s.demand, math.MaxInt-n) + n
+	s.cond.Broadcast()
+}
+
+func (s *demandSubscription) Cancel() {
+	s.mu.Lock()
+	s.canceled = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	s.cancel()
+}
+
+// waits for the demand of the Subscriber, returning false once cancelled
+func (s *demandSubscription) acquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.demand == 0 && !s.canceled {
+		s.cond.Wait()
+	}
+	if s.canceled {
+		return false
+	}
+	s.demand--
+	return true
+}
+
+func (s *demandSubscription) cancelled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.canceled
+}
+
+// a Subscriber buffering the elements it requests into a channel, closed once the Publisher completes
+type bufferingSubscriber[T any] struct {
+	bufferSize   int
+	elems        chan T
+	subscription Subscription
+	err          error
+}
+
+func (s *bufferingSubscriber[T]) OnSubscribe(subscription Subscription) {
+	s.subscription = subscription
+	subscription.Request(s.bufferSize)
+}
+
+func (s *bufferingSubscriber[T]) OnNext(elem T) {
+	s.elems <- elem
+}
+
+func (s *bufferingSubscriber[T]) OnError(err error) {
+	s.err = err
+	close(s.elems)
+}
+
+func (s *bufferingSubscriber[T]) OnComplete() {
+	close(s.elems)
+}
+
+// a Publisher stage, wrapping each downstream Subscriber into a Subscriber of its upstream Publisher
+type stage[T any, R any] struct {
+	up   Publisher[T]
+	wrap func(down Subscriber[R]) Subscriber[T]
+}
+
+func (p *stage[T, R]) Subscribe(subscriber Subscriber[R]) {
+	p.up.Subscribe(p.wrap(subscriber))
+}
+
+// the Subscriber of a MapPublisher stage
+type mapSubscriber[T any, R any] struct {
+	Subscriber[R]
+	mapper func(T) R
+}
+
+func (s *mapSubscriber[T, R]) OnNext(elem T) {
+	s.Subscriber.OnNext(s.mapper(elem))
+}
+
+// the Subscriber of a FilterPublisher stage
+type filterSubscriber[T any] struct {
+	down      Subscriber[T]
+	predicate func(T) bool
+	up        Subscription
+}
+
+func (s *filterSubscriber[T]) OnSubscribe(subscription Subscription) {
+	s.up = subscription
+	s.down.OnSubscribe(subscription)
+}
+
+func (s *filterSubscriber[T]) OnNext(elem T) {
+	if s.predicate(elem) {
+		s.down.OnNext(elem)
+	} else {
+		s.up.Request(1)
+	}
+}
+
+func (s *filterSubscriber[T]) OnError(err error) {
+	s.down.OnError(err)
+}
+
+func (s *filterSubscriber[T]) OnComplete() {
+	s.down.OnComplete()
+}
+
+// the Subscriber of a FlatMapPublisher stage, also the Subscription of its downstream Subscriber.
+// Signals are sent downstream by a single goroutine at a time, the emitting one, from the pending elements.
+type flatMapSubscriber[T any, R any] struct {
+	down   Subscriber[R]
+	mapper func(T) *Stream[R]
+	up     Subscription
+
+	mu       sync.Mutex
+	pending  []R
+	demand   int
+	inFlight bool
+	emitting bool
+	done     bool
+	err      error
+	canceled bool
+}
+
+func (s *flatMapSubscriber[T, R]) OnSubscribe(subscription Subscription) {
+	s.up = subscription
+	s.down.OnSubscribe(s)
+}
+
+func (s *flatMapSubscriber[T, R]) OnNext(elem T) {
+	elems := s.mapper(elem).ToSlice()
+	s.mu.Lock()
+	s.pending, s.inFlight = append(s.pending, elems...), false
+	s.mu.Unlock()
+	s.drain()
+}
+
+func (s *flatMapSubscriber[T, R]) OnError(err error) {
+	s.mu.Lock()
+	s.done, s.err, s.pending = true, err, nil
+	s.mu.Unlock()
+	s.drain()
+}
+
+func (s *flatMapSubscriber[T, R]) OnComplete() {
+	s.mu.Lock()
+	s.done = true
+	s.mu.Unlock()
+	s.drain()
+}
+
+func (s *flatMapSubscriber[T, R]) Request(n int) {
+	if n <= 0 {
+		return
+	}
+	s.mu.Lock()
+	s.demand = min(s.demand, math.MaxInt-n) + n
+	s.mu.Unlock()
+	s.drain()
+}
+
+func (s *flatMapSubscriber[T, R]) Cancel() {
+	s.mu.Lock()
+	s.canceled, s.pending = true, nil
+	s.mu.Unlock()
+	s.up.Cancel()
+}
+
+// sends the pending elements and signals downstream, unless another goroutine is already sending them
+func (s *flatMapSubscriber[T, R]) drain() {
+	s.mu.Lock()
+	if s.emitting {
+		s.mu.Unlock()
+		return
+	}
+	s.emitting = true
+	for !s.canceled {
+		switch {
+		case s.demand > 0 && len(s.pending) > 0:
+			elem := s.pending[0]
+			s.pending, s.demand = s.pending[1:], s.demand-1
+			s.mu.Unlock()
+			s.down.OnNext(elem)
+			s.mu.Lock()
+		case s.done && len(s.pending) == 0:
+			// stays emitting: no more signals are sent
+			s.canceled = true
+			s.mu.Unlock()
+			if s.err != nil {
+				s.down.OnError(s.err)
+			} else {
+				s.down.OnComplete()
+			}
+			return
+		case s.demand > 0 && !s.inFlight && !s.done:
+			s.inFlight = true
+			s.mu.Unlock()
+			s.up.Request(1)
+			s.mu.Lock()
+		default:
+			s.emitting = false
+			s.mu.Unlock()
+			return
+		}
+	}
+	s.mu.Unlock()
+}
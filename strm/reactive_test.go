@@ -0,0 +1,206 @@
+package strm
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPublisherDemand(t *testing.T) {
+	// prepare
+	subscriber := newRecordingSubscriber[int]()
+
+	// call
+	Of(1, 2, 3, 4, 5).ToPublisher().Subscribe(subscriber)
+	subscriber.subscription.Request(2)
+	first := subscriber.await(2)
+	subscriber.subscription.Request(10)
+	all := subscriber.awaitDone()
+
+	// assert
+	assert.Equal(t, []int{1, 2}, first, "wrong requested elements")
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, all, "wrong elements")
+	assert.True(t, subscriber.completed, "subscriber should complete")
+	assert.NoError(t, subscriber.err)
+}
+
+func TestPublisherBackpressure(t *testing.T) {
+	// prepare
+	p, produced, stopped := countingPublisher()
+	subscriber := newRecordingSubscriber[int]()
+
+	// call
+	p.Subscribe(subscriber)
+	subscriber.subscription.Request(3)
+	received := subscriber.await(3)
+	subscriber.subscription.Cancel()
+	<-stopped
+
+	// assert
+	assert.Equal(t, []int{0, 1, 2}, received, "wrong elements")
+	assert.Equal(t, 3, *produced, "the producer should wait for the demand")
+}
+
+func TestPublisherFromChanToStream(t *testing.T) {
+	// prepare
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 1; i <= 10; i++ {
+			ch <- i
+		}
+	}()
+	p := FilterPublisher(PublisherFromChan(ch), func(n int) bool { return n%2 == 0 })
+
+	// call
+	result, err := ToStream(MapPublisher(p, strconv.Itoa), 2).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "4", "6", "8", "10"}, result, "wrong elements")
+}
+
+func TestToStreamBoundedBuffer(t *testing.T) {
+	// prepare
+	p, produced, stopped := countingPublisher()
+
+	// call
+	first, ok := ToStream(p, 4).First()
+	<-stopped
+
+	// assert
+	assert.True(t, ok, "missing first element")
+	assert.Equal(t, 0, first, "wrong first element")
+	assert.Equal(t, 4, *produced, "only the buffered elements should be produced")
+}
+
+// returns a Publisher of the natural numbers, counting the ones sent in [produced], which is read once [stopped]
+// is closed, when the Subscription is cancelled
+func countingPublisher() (p Publisher[int], produced *int, stopped <-chan struct{}) {
+	produced, done := new(int), make(chan struct{})
+	p = NewPublisher(func(ctx context.Context, emit func(int) bool) error {
+		defer close(done)
+		for i := 0; emit(i); i++ {
+			*produced++
+		}
+		return nil
+	})
+	return p, produced, done
+}
+
+func TestLazyStreamToPublisherClose(t *testing.T) {
+	// prepare
+	var closes atomic.Int32
+	s := closing(&closes, 1, 2)
+
+	// call
+	s.ToPublisher()
+	err := s.Close()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), closes.Load(), "closing should release the source of a Publisher never subscribed to")
+}
+
+func TestFlatMapPublisher(t *testing.T) {
+	// prepare
+	p := FlatMapPublisher(Of(1, 2, 3).ToPublisher(), func(n int) *Stream[int] {
+		return Of(make([]int, n)...).ApplyOnEach(func(int) int { return n })
+	})
+
+	// call
+	result, err := ToStream(p, 1).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 2, 3, 3, 3}, result, "wrong elements")
+}
+
+func TestPublisherErrors(t *testing.T) {
+	// prepare
+	produceErr := errors.New("socket closed")
+	p := NewPublisher(func(_ context.Context, emit func(string) bool) error {
+		emit("a")
+		return produceErr
+	})
+	lazy := Lines(&failingReader{produceErr}).ToPublisher()
+	subscriber := newRecordingSubscriber[string]()
+
+	// call
+	result, err := ToStream(FlatMapPublisher(p, func(s string) *Stream[string] { return Of(s, s) }), 4).ToSlice()
+	_, lazyErr := ToStream(lazy, 1).ToSlice()
+	lazy.Subscribe(subscriber)
+	subscriber.awaitDone()
+
+	// assert
+	assert.Equal(t, []string{"a", "a"}, result, "wrong elements before the error")
+	assert.ErrorIs(t, err, produceErr, "wrong error")
+	assert.ErrorIs(t, lazyErr, produceErr, "wrong lazy source error")
+	assert.ErrorIs(t, subscriber.err, ErrStreamConsumed, "wrong second subscription error")
+}
+
+// a Subscriber recording the elements and signals it receives, requesting nothing by itself
+type recordingSubscriber[T any] struct {
+	subscription Subscription
+	mu           sync.Mutex
+	cond         *sync.Cond
+	elems        []T
+	completed    bool
+	err          error
+}
+
+func newRecordingSubscriber[T any]() *recordingSubscriber[T] {
+	s := &recordingSubscriber[T]{}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *recordingSubscriber[T]) OnSubscribe(subscription Subscription) {
+	s.subscription = subscription
+}
+
+func (s *recordingSubscriber[T]) OnNext(elem T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elems = append(s.elems, elem)
+	s.cond.Broadcast()
+}
+
+func (s *recordingSubscriber[T]) OnError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	s.cond.Broadcast()
+}
+
+func (s *recordingSubscriber[T]) OnComplete() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed = true
+	s.cond.Broadcast()
+}
+
+// waits for [n] elements, returning the received ones
+func (s *recordingSubscriber[T]) await(n int) []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.elems) < n {
+		s.cond.Wait()
+	}
+	return append([]T(nil), s.elems...)
+}
+
+// waits for the Publisher to complete, returning the received elements
+func (s *recordingSubscriber[T]) awaitDone() []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for !s.completed && s.err == nil {
+		s.cond.Wait()
+	}
+	return append([]T(nil), s.elems...)
+}
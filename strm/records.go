@@ -0,0 +1,53 @@
+package strm
+
+import (
+	"fmt"
+)
+
+// RecordError An error decoding a record read by a LazyStream source, e.g. a CSV row
+type RecordError struct {
+	// Record the position of the record among the ones read by the source, starting at 1
+	Record int
+	// Field the name of the field which failed decoding, if known
+	Field string
+	Err   error
+}
+
+func (e *RecordError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("strm: record %d: %v", e.Record, e.Err)
+	}
+	return fmt.Sprintf("strm: record %d, field %s: %v", e.Record, e.Field, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorPolicy Decides what a LazyStream source does with a malformed record: skipping it when returning true,
+// or stopping with the given error otherwise, which is then returned by LazyStream.Err
+type ErrorPolicy func(err *RecordError) bool
+
+var (
+	// StopOnError The default ErrorPolicy, stopping the LazyStream at the first malformed record
+	StopOnError ErrorPolicy = func(*RecordError) bool { return false }
+	// SkipInvalid An ErrorPolicy skipping every malformed record
+	SkipInvalid ErrorPolicy = func(*RecordError) bool { return true }
+)
+
+// CollectErrors Returns an ErrorPolicy skipping every malformed record, appending its error to the given [errs]
+func CollectErrors(errs *[]*RecordError) ErrorPolicy {
+	return func(err *RecordError) bool {
+		*errs = append(*errs, err)
+		return true
+	}
+}
+
+/*
+ * Internal Ops
+ */
+
+// applies this ErrorPolicy to the given error, a nil ErrorPolicy stopping on every error
+func (p ErrorPolicy) skip(err *RecordError) bool {
+	return p != nil && p(err)
+}
@@ -0,0 +1,37 @@
+package strm
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRecordError(t *testing.T) {
+	// prepare
+	cause := errors.New("bad value")
+
+	// call
+	withField := &RecordError{Record: 3, Field: "age", Err: cause}
+	withoutField := &RecordError{Record: 4, Err: cause}
+
+	// assert
+	assert.EqualError(t, withField, "strm: record 3, field age: bad value", "wrong message")
+	assert.EqualError(t, withoutField, "strm: record 4: bad value", "wrong message")
+	assert.ErrorIs(t, withField, cause, "wrong cause")
+}
+
+func TestErrorPolicies(t *testing.T) {
+	// prepare
+	var errs []*RecordError
+	err := &RecordError{Record: 1, Err: errors.New("bad value")}
+
+	// call
+	collected := CollectErrors(&errs).skip(err)
+
+	// assert
+	assert.False(t, ErrorPolicy(nil).skip(err), "nil policy should stop")
+	assert.False(t, StopOnError.skip(err), "StopOnError should stop")
+	assert.True(t, SkipInvalid.skip(err), "SkipInvalid should skip")
+	assert.True(t, collected, "CollectErrors should skip")
+	assert.Equal(t, []*RecordError{err}, errs, "wrong collected errors")
+}
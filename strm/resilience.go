@@ -0,0 +1,333 @@
+package strm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCircuitOpen Returned by the calls rejected by an open CircuitBreaker
+	ErrCircuitOpen = errors.New("strm: circuit open")
+	// ErrCallTimeout Returned by the calls not completed in time, see CallTimeout
+	ErrCallTimeout = errors.New("strm: call timeout")
+)
+
+// FallibleMapper A mapper which may fail, e.g. calling a remote service, and should give up once [ctx] is done
+type FallibleMapper[IN any, OUT any] func(ctx context.Context, elem IN) (OUT, error)
+
+// Policy A resilience policy around the calls of a FallibleMapper, running the given [call] as it sees fit
+type Policy func(ctx context.Context, call func(ctx context.Context) error) error
+
+// Resilient Returns a FallibleMapper calling the given one [f] through the given [policies], the first one being
+// the outermost. The usual order is Retry, CircuitBreak, RateLimit, and finally CallTimeout, so that each attempt
+// is limited, timed and counted by the CircuitBreaker.
+func Resilient[IN any, OUT any](f FallibleMapper[IN, OUT], policies ...Policy) FallibleMapper[IN, OUT] {
+	return func(ctx context.Context, elem IN) (OUT, error) {
+		// calls abandoned by a policy, e.g. timed out, may still complete concurrently
+		var mu sync.Mutex
+		var out OUT
+		done := false
+		call := func(ctx context.Context) error {
+			value, err := f(ctx, elem)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil && !done {
+				out = value
+			}
+			return err
+		}
+		for i := len(policies) - 1; i >= 0; i-- {
+			policy, next := policies[i], call
+			call = func(ctx context.Context) error { return policy(ctx, next) }
+		}
+		err := call(ctx)
+		mu.Lock()
+		defer mu.Unlock()
+		done = true
+		if err != nil {
+			var zero OUT
+			return zero, err
+		}
+		return out, nil
+	}
+}
+
+// TryPMap Returns a new Stream containing the results of applying the given FallibleMapper to each element in the
+// given Stream in parallel, see PMap. Once a call fails, the context of the other calls is cancelled, and the error of
+// the first failed call is returned, with the index of its element.
+func TryPMap[IN any, OUT any](ctx context.Context, s *Stream[IN], f FallibleMapper[IN, OUT], batching ...bool) (*Stream[OUT], error) {
+	defer s.exit(s.enter("TryPMap", true))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var once sync.Once
+	results := PMap(s, func(elem IN) (r result[OUT]) {
+		if r.err = ctx.Err(); r.err != nil {
+			return
+		}
+		if r.value, r.err = f(ctx, elem); r.err != nil {
+			once.Do(func() {
+				r.first = true
+				cancel()
+			})
+		}
+		return
+	}, batching...)
+
+	values := make([]OUT, len(results.slice))
+	for i, r := range results.slice {
+		if r.first {
+			return nil, s.failed(fmt.Errorf("strm: mapping element at index %d: %w", i, r.err))
+		}
+		values[i] = r.value
+	}
+	return derive(s, values), nil
+}
+
+/*
+ * Retry
+ */
+
+// RetryOptions The options of the Retry Policy, its zero value retrying 3 times any error after 100ms, 200ms and 400ms
+type RetryOptions struct {
+	// MaxAttempts the maximum number of calls, including the first one, 4 when zero
+	MaxAttempts int
+	// InitialBackoff the delay before the first retry, 100ms when zero
+	InitialBackoff time.Duration
+	// MaxBackoff the maximum delay between retries, unlimited when zero
+	MaxBackoff time.Duration
+	// Multiplier the growth factor of the delay after each retry, 2 when zero
+	Multiplier float64
+	// Jitter the random fraction, between 0 and 1, removed from each delay to spread the retries of concurrent calls
+	Jitter float64
+	// Retryable reports whether an error is worth retrying, all errors being retried when nil
+	Retryable func(err error) bool
+	// Clock SystemClock when nil
+	Clock Clock
+	// Rand the source of the jitter, a randomly seeded one when nil
+	Rand *rand.Rand
+}
+
+// Retry Returns a Policy retrying the failed calls with an exponential backoff, as configured by the given [opts].
+// Retries stop once the context is done, returning the last error of the call.
+func Retry(opts RetryOptions) Policy {
+	attempts, backoff, multiplier := opts.MaxAttempts, opts.InitialBackoff, opts.Multiplier
+	if attempts <= 0 {
+		attempts = 4
+	}
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	clock := orSystemClock(opts.Clock)
+	var mu sync.Mutex
+	rng := orRandom(opts.Rand)
+
+	return func(ctx context.Context, call func(ctx context.Context) error) error {
+		delay := backoff
+		for attempt := 1; ; attempt++ {
+			err := call(ctx)
+			if err == nil || attempt == attempts || (opts.Retryable != nil && !opts.Retryable(err)) {
+				return err
+			}
+			wait := delay
+			if opts.Jitter > 0 {
+				mu.Lock()
+				wait -= time.Duration(float64(wait) * min(opts.Jitter, 1) * rng.Float64())
+				mu.Unlock()
+			}
+			select {
+			case <-clock.After(wait):
+			case <-ctx.Done():
+				return err
+			}
+			// float64(math.MaxInt64) rounds up to 2^63, which overflows a Duration: the maximum is assigned instead
+			if next := float64(delay) * multiplier; next >= math.MaxInt64 {
+				delay = time.Duration(math.MaxInt64)
+			} else {
+				delay = time.Duration(next)
+			}
+			if opts.MaxBackoff > 0 {
+				delay = min(delay, opts.MaxBackoff)
+			}
+		}
+	}
+}
+
+/*
+ * Timeout
+ */
+
+// CallTimeout Returns a Policy failing the calls not completed within the given [d] with ErrCallTimeout, measured
+// by the given [clock], SystemClock when nil. The context of a timed out call is cancelled, and its result discarded.
+func CallTimeout(d time.Duration, clock Clock) Policy {
+	clock = orSystemClock(clock)
+	return func(ctx context.Context, call func(ctx context.Context) error) error {
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		done := make(chan error, 1)
+		go func() { done <- call(ctx) }()
+		select {
+		case err := <-done:
+			return err
+		case <-clock.After(d):
+			cancel(ErrCallTimeout)
+			return ErrCallTimeout
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+/*
+ * Rate Limiting
+ */
+
+// TokenBucket A rate limiter shared by concurrent calls, e.g. by the workers of PMap: it holds up to [burst] tokens,
+// refilled at [rate] tokens per second, each call taking one
+type TokenBucket struct {
+	mu     sync.Mutex
+	clock  Clock
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket Creates a new full TokenBucket allowing [rate] calls per second, and bursts of up to [burst] calls,
+// measured by the given [clock], SystemClock when nil
+func NewTokenBucket(rate float64, burst int, clock Clock) *TokenBucket {
+	clock = orSystemClock(clock)
+	burst = max(burst, 1)
+	return &TokenBucket{clock: clock, rate: rate, burst: float64(burst), tokens: float64(burst), last: clock.Now()}
+}
+
+// Wait Takes a token from this TokenBucket, waiting for one to be refilled if needed, or returns the error of the
+// given [ctx] once it's done
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		wait := b.take()
+		if wait == 0 {
+			return nil
+		}
+		select {
+		case <-b.clock.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// RateLimit Returns a Policy waiting for a token of the given TokenBucket [limiter] before each call
+func RateLimit(limiter *TokenBucket) Policy {
+	return func(ctx context.Context, call func(ctx context.Context) error) error {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+		return call(ctx)
+	}
+}
+
+// takes a token, returning 0, or returns the time until one is refilled
+func (b *TokenBucket) take() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return max(time.Duration((1-b.tokens)/b.rate*float64(time.Second)), time.Nanosecond)
+}
+
+/*
+ * Circuit Breaking
+ */
+
+// CircuitBreaker Stops calling a failing dependency: after [failures] consecutive failed calls it opens, rejecting
+// the calls with ErrCircuitOpen during its [cooldown], then lets a single trial call through, closing again once
+// a trial call succeeds
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	clock     Clock
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// NewCircuitBreaker Creates a new closed CircuitBreaker, opening after [failures] consecutive failed calls for the
+// given [cooldown], measured by the given [clock], SystemClock when nil
+func NewCircuitBreaker(failures int, cooldown time.Duration, clock Clock) *CircuitBreaker {
+	return &CircuitBreaker{clock: orSystemClock(clock), threshold: max(failures, 1), cooldown: cooldown}
+}
+
+// Open Returns true while this CircuitBreaker rejects the calls
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.threshold && b.clock.Now().Before(b.openUntil)
+}
+
+// CircuitBreak Returns a Policy calling through the given CircuitBreaker [breaker]
+func CircuitBreak(breaker *CircuitBreaker) Policy {
+	return func(ctx context.Context, call func(ctx context.Context) error) error {
+		trial, ok := breaker.allow()
+		if !ok {
+			return ErrCircuitOpen
+		}
+		err := call(ctx)
+		breaker.record(err, trial)
+		return err
+	}
+}
+
+// returns true if a call is allowed, and whether it's the trial call let through once the cooldown elapsed
+func (b *CircuitBreaker) allow() (trial bool, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return false, true
+	}
+	if b.trial || b.clock.Now().Before(b.openUntil) {
+		return false, false
+	}
+	b.trial = true
+	return true, true
+}
+
+// records the result of an allowed call, a [trial] one letting another trial call through once it failed
+func (b *CircuitBreaker) record(err error, trial bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if trial {
+		b.trial = false
+	}
+	if err == nil {
+		b.failures = 0
+		return
+	}
+	if b.failures++; b.failures >= b.threshold {
+		b.openUntil = b.clock.Now().Add(b.cooldown)
+	}
+}
+
+/*
+ * Internal Ops
+ */
+
+// the result of a FallibleMapper call, first if it's the first failed one
+type result[OUT any] struct {
+	value OUT
+	err   error
+	first bool
+}
@@ -0,0 +1,311 @@
+package strm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errUnavailable = errors.New("service unavailable")
+
+func TestRetryBackoff(t *testing.T) {
+	// prepare
+	clock := &recordingClock{}
+	attempts := 0
+	f := Resilient(func(_ context.Context, n int) (int, error) {
+		if attempts++; attempts < 4 {
+			return 0, errUnavailable
+		}
+		return n * 2, nil
+	}, Retry(RetryOptions{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second, Clock: clock}))
+
+	// call
+	result, err := f(context.Background(), 21)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, 42, result, "wrong result")
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, clock.waits, "wrong backoff")
+}
+
+func TestRetryJitter(t *testing.T) {
+	// prepare
+	clock := &recordingClock{}
+	f := Resilient(func(context.Context, int) (int, error) { return 0, errUnavailable },
+		Retry(RetryOptions{MaxAttempts: 5, InitialBackoff: time.Second, Multiplier: 3, Jitter: 0.5, Clock: clock,
+			Rand: rand.New(rand.NewPCG(1, 2))}))
+
+	// call
+	_, err := f(context.Background(), 1)
+
+	// assert
+	assert.ErrorIs(t, err, errUnavailable, "the last error should be returned")
+	require.Len(t, clock.waits, 4, "wrong number of retries")
+	for i, backoff := range []time.Duration{time.Second, 3 * time.Second, 9 * time.Second, 27 * time.Second} {
+		assert.LessOrEqual(t, clock.waits[i], backoff, "jitter should shorten the backoff")
+		assert.GreaterOrEqual(t, clock.waits[i], backoff/2, "jitter should be bounded")
+	}
+	assert.NotEqual(t, time.Second, clock.waits[0], "jitter should apply")
+}
+
+func TestRetryBackoffOverflow(t *testing.T) {
+	// prepare
+	clock := &recordingClock{}
+	f := Resilient(func(context.Context, int) (int, error) { return 0, errUnavailable },
+		Retry(RetryOptions{MaxAttempts: 100, InitialBackoff: time.Second, Multiplier: 10, Clock: clock}))
+
+	// call
+	_, err := f(context.Background(), 1)
+
+	// assert
+	assert.ErrorIs(t, err, errUnavailable, "the last error should be returned")
+	require.Len(t, clock.waits, 99, "wrong number of retries")
+	for i := 1; i < len(clock.waits); i++ {
+		assert.GreaterOrEqual(t, clock.waits[i], clock.waits[i-1], "the backoff shouldn't overflow")
+	}
+	assert.Equal(t, time.Duration(math.MaxInt64), clock.waits[98], "the backoff should be capped")
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	// prepare
+	calls := 0
+	permanent := errors.New("bad request")
+	f := Resilient(func(context.Context, int) (int, error) {
+		calls++
+		return 0, permanent
+	}, Retry(RetryOptions{Retryable: func(err error) bool { return !errors.Is(err, permanent) }}))
+
+	// call
+	_, err := f(context.Background(), 1)
+
+	// assert
+	assert.ErrorIs(t, err, permanent, "wrong error")
+	assert.Equal(t, 1, calls, "non retryable errors shouldn't be retried")
+}
+
+func TestRetryCancelled(t *testing.T) {
+	// prepare
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f := Resilient(func(context.Context, int) (int, error) { return 0, errUnavailable }, Retry(RetryOptions{InitialBackoff: time.Hour}))
+
+	// call
+	_, err := f(ctx, 1)
+
+	// assert
+	assert.ErrorIs(t, err, errUnavailable, "the last error should be returned once the context is done")
+}
+
+func TestCallTimeout(t *testing.T) {
+	// prepare
+	clock := NewManualClock(windowOrigin)
+	cancelled := make(chan error, 1)
+	f := Resilient(func(ctx context.Context, n int) (int, error) {
+		if n == 0 {
+			return 1, nil
+		}
+		<-ctx.Done()
+		cancelled <- context.Cause(ctx)
+		return 0, ctx.Err()
+	}, CallTimeout(time.Second, clock))
+
+	// call
+	fast, fastErr := f(context.Background(), 0)
+	done := make(chan error)
+	go func() {
+		_, err := f(context.Background(), 1)
+		done <- err
+	}()
+	// the timer of the fast call is still pending
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+
+	// assert
+	require.NoError(t, fastErr)
+	assert.Equal(t, 1, fast, "wrong result")
+	assert.ErrorIs(t, <-done, ErrCallTimeout, "wrong error")
+	assert.ErrorIs(t, <-cancelled, ErrCallTimeout, "the call context should be cancelled")
+}
+
+func TestTokenBucket(t *testing.T) {
+	// prepare
+	clock := NewManualClock(windowOrigin)
+	bucket := NewTokenBucket(2, 3, clock)
+	var calls atomic.Int32
+	f := Resilient(func(context.Context, int) (int, error) {
+		calls.Add(1)
+		return 0, nil
+	}, RateLimit(bucket))
+
+	// call
+	for range 3 {
+		_, _ = f(context.Background(), 0)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = f(context.Background(), 0)
+	}()
+	clock.BlockUntil(1)
+	burst := calls.Load()
+	clock.Advance(500 * time.Millisecond)
+	<-done
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	waitErr := bucket.Wait(ctx)
+
+	// assert
+	assert.Equal(t, int32(3), burst, "the burst should be allowed at once")
+	assert.Equal(t, int32(4), calls.Load(), "a call should wait for the refill")
+	assert.ErrorIs(t, waitErr, context.Canceled, "wrong error")
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	// prepare
+	clock := NewManualClock(windowOrigin)
+	breaker := NewCircuitBreaker(2, time.Minute, clock)
+	failing := true
+	calls := 0
+	f := Resilient(func(context.Context, int) (int, error) {
+		calls++
+		if failing {
+			return 0, errUnavailable
+		}
+		return 1, nil
+	}, CircuitBreak(breaker))
+
+	// call & assert
+	_, err1 := f(context.Background(), 0)
+	_, err2 := f(context.Background(), 0)
+	_, err3 := f(context.Background(), 0)
+	assert.ErrorIs(t, err1, errUnavailable)
+	assert.ErrorIs(t, err2, errUnavailable)
+	assert.ErrorIs(t, err3, ErrCircuitOpen, "the circuit should open after consecutive failures")
+	assert.True(t, breaker.Open(), "wrong breaker state")
+	assert.Equal(t, 2, calls, "open circuits shouldn't call")
+
+	clock.Advance(time.Minute)
+	_, trialErr := f(context.Background(), 0)
+	_, reopenedErr := f(context.Background(), 0)
+	assert.ErrorIs(t, trialErr, errUnavailable, "a trial call should be let through after the cooldown")
+	assert.ErrorIs(t, reopenedErr, ErrCircuitOpen, "a failed trial should reopen the circuit")
+
+	clock.Advance(time.Minute)
+	failing = false
+	result, err := f(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result, "wrong result")
+	assert.False(t, breaker.Open(), "a successful trial should close the circuit")
+}
+
+func TestCircuitBreakerSingleTrial(t *testing.T) {
+	// prepare
+	clock := NewManualClock(windowOrigin)
+	breaker := NewCircuitBreaker(1, time.Minute, clock)
+	_, _ = breaker.allow() // a slow call, completing once the circuit is open
+	_, _ = breaker.allow()
+	breaker.record(errUnavailable, false)
+	clock.Advance(time.Minute)
+
+	// call
+	trial, allowed := breaker.allow()
+	breaker.record(errUnavailable, false)
+	_, secondAllowed := breaker.allow()
+
+	// assert
+	assert.True(t, trial && allowed, "a trial call should be let through after the cooldown")
+	assert.False(t, secondAllowed, "the slow call shouldn't let another trial call through")
+}
+
+func TestTryPMapWithPolicies(t *testing.T) {
+	// prepare
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1)%3 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprint(w, len(r.URL.Query().Get("q")))
+	}))
+	defer server.Close()
+	lookup := func(ctx context.Context, q string) (int, error) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?q="+q, nil)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return 0, errUnavailable
+		}
+		var n int
+		_, err = fmt.Fscan(resp.Body, &n)
+		return n, err
+	}
+	f := Resilient(lookup,
+		Retry(RetryOptions{InitialBackoff: time.Millisecond, Jitter: 0.5}),
+		CircuitBreak(NewCircuitBreaker(100, time.Second, nil)),
+		RateLimit(NewTokenBucket(10_000, 10, nil)),
+		CallTimeout(5*time.Second, nil))
+
+	// call
+	lengths, err := TryPMap(context.Background(), Of("a", "bb", "ccc", "dddd", "eeeee", "ffffff"), f)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, lengths.ToSlice(), "wrong results")
+	assert.Greater(t, requests.Load(), int32(6), "failed requests should be retried")
+}
+
+func TestTryPMapError(t *testing.T) {
+	// prepare
+	var completed atomic.Int32
+	f := func(ctx context.Context, n int) (string, error) {
+		if n == 3 {
+			return "", errUnavailable
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+			completed.Add(1)
+			return strconv.Itoa(n), nil
+		}
+	}
+
+	// call
+	result, err := TryPMap(context.Background(), Of(1, 2, 3, 4), f)
+
+	// assert
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "strm: mapping element at index 2: service unavailable", "wrong error")
+	assert.ErrorIs(t, err, errUnavailable, "wrong cause")
+	assert.Zero(t, completed.Load(), "the other calls should be cancelled")
+}
+
+// a Clock recording the durations it's asked to wait for, without waiting
+type recordingClock struct {
+	waits []time.Duration
+}
+
+func (c *recordingClock) Now() time.Time {
+	return windowOrigin
+}
+
+func (c *recordingClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	ch <- windowOrigin
+	return ch
+}
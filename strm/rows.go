@@ -0,0 +1,101 @@
+package strm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FromRows Creates a new LazyStream of the given database [rows], each scanned into a T by the given [scan] function,
+// e.g. calling rows.Scan. The rows are closed once a terminal operation ends, even if it didn't read all of them,
+// or by LazyStream.Close. Errors of [scan], reported as RecordError, and of the rows stop the LazyStream
+// and are returned by LazyStream.Err or the fallible terminal operations, like ToSlice.
+func FromRows[T any](rows *sql.Rows, scan func(rows *sql.Rows) (T, error)) *LazyStream[T] {
+	s := &LazyStream[T]{src: &lazySource{closer: rows.Close}}
+	s.seq = func(yield func(T) bool) {
+		for record := 1; rows.Next(); record++ {
+			elem, err := scan(rows)
+			if err != nil {
+				s.src.fail(&RecordError{Record: record, Err: err})
+				return
+			}
+			if !yield(elem) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			s.src.fail(err)
+		}
+	}
+	return s
+}
+
+// FromRowsAuto Creates a new LazyStream of the given database [rows], see FromRows, each scanned into a T
+// by the names of its columns: struct fields are mapped by the names in their `db:"name"` tags, or their field names,
+// case-insensitively, and fields tagged `db:"-"` are ignored. Columns without a field are discarded.
+// Non-struct types are scanned from the single column of the rows, e.g. for `SELECT id FROM users`.
+func FromRowsAuto[T any](rows *sql.Rows) *LazyStream[T] {
+	var targets func(elem *T) []any
+	return FromRows(rows, func(rows *sql.Rows) (elem T, err error) {
+		if targets == nil {
+			if targets, err = scanTargets[T](rows); err != nil {
+				return
+			}
+		}
+		err = rows.Scan(targets(&elem)...)
+		return
+	})
+}
+
+/*
+ * Internal Ops
+ */
+
+// returns a function returning the scan destinations of the columns of the given rows, in the given element
+func scanTargets[T any](rows *sql.Rows) (func(elem *T) []any, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct || t == timeType || t.Implements(scannerType) || reflect.PointerTo(t).Implements(scannerType) {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("strm: scanning %d columns into %v, expected a single one", len(columns), t)
+		}
+		return func(elem *T) []any { return []any{elem} }, nil
+	}
+
+	fieldIndexes := make([][]int, len(columns))
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous || len(f.Index) > 1 && viaPointer(t, f.Index) {
+			continue
+		}
+		name := f.Tag.Get("db")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		for i, column := range columns {
+			if fieldIndexes[i] == nil && strings.EqualFold(column, name) {
+				fieldIndexes[i] = f.Index
+			}
+		}
+	}
+	return func(elem *T) []any {
+		v := reflect.ValueOf(elem).Elem()
+		targets := make([]any, len(columns))
+		for i, index := range fieldIndexes {
+			if index == nil {
+				targets[i] = new(any)
+			} else {
+				targets[i] = v.FieldByIndex(index).Addr().Interface()
+			}
+		}
+		return targets
+	}, nil
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
@@ -0,0 +1,216 @@
+package strm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"sync"
+	"testing"
+)
+
+type dbUser struct {
+	ID      int64  `db:"id"`
+	Name    string `db:"user_name"`
+	Team    sql.NullString
+	Ignored string `db:"-"`
+}
+
+func TestFromRows(t *testing.T) {
+	// prepare
+	db, closed := openFakeDB(t, fakeResult{
+		columns: []string{"id", "user_name"},
+		rows:    [][]driver.Value{{int64(1), "ann"}, {int64(2), "bob"}, {int64(3), "cid"}},
+	})
+	rows, err := db.Query("users")
+	require.NoError(t, err)
+
+	// call
+	names, err := FromRows(rows, func(rows *sql.Rows) (name string, err error) {
+		var id int64
+		err = rows.Scan(&id, &name)
+		return
+	}).Filter(func(name string) bool { return name != "bob" }).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ann", "cid"}, names, "wrong names")
+	assert.True(t, closed(), "rows should be closed")
+}
+
+func TestFromRowsAbandoned(t *testing.T) {
+	// prepare
+	db, closed := openFakeDB(t, fakeResult{
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}},
+	})
+	taken, _ := db.Query("ids")
+	unused, _ := db.Query("ids")
+
+	// call
+	first, ok := FromRowsAuto[int64](taken).First()
+	s := FromRowsAuto[int64](unused)
+	closeErr := s.Close()
+
+	// assert
+	assert.True(t, ok, "missing first id")
+	assert.Equal(t, int64(1), first, "wrong first id")
+	assert.NoError(t, closeErr)
+	assert.True(t, closed(), "rows should be closed")
+}
+
+func TestFromRowsAuto(t *testing.T) {
+	// prepare
+	db, _ := openFakeDB(t, fakeResult{
+		columns: []string{"ID", "extra", "USER_NAME", "team"},
+		rows:    [][]driver.Value{{int64(1), "x", "ann", "core"}, {int64(2), "y", "bob", nil}, {int64(3), "z", "cid", "core"}},
+	})
+	rows, err := db.Query("users")
+	require.NoError(t, err)
+
+	// call
+	users, err := FromRowsAuto[dbUser](rows).Collect()
+	byTeam := GroupBy(users, func(u dbUser) string { return u.Team.String })
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]dbUser{
+		"core": {{1, "ann", sql.NullString{String: "core", Valid: true}, ""}, {3, "cid", sql.NullString{String: "core", Valid: true}, ""}},
+		"":     {{ID: 2, Name: "bob"}},
+	}, byTeam, "wrong users by team")
+}
+
+func TestFromRowsErrors(t *testing.T) {
+	// prepare
+	rowsErr := errors.New("connection lost")
+	db, closed := openFakeDB(t, fakeResult{
+		columns: []string{"id", "user_name"},
+		rows:    [][]driver.Value{{int64(1), "ann"}, {"two", "bob"}},
+	}, fakeResult{
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(1)}},
+		err:     rowsErr,
+	}, fakeResult{
+		columns: []string{"id", "user_name"},
+		rows:    [][]driver.Value{{int64(1), "ann"}},
+	})
+	unscannable, _ := db.Query("users")
+	failing, _ := db.Query("ids")
+	multiColumn, _ := db.Query("users")
+
+	// call
+	users, scanErr := FromRowsAuto[dbUser](unscannable).ToSlice()
+	ids, failingErr := FromRowsAuto[int64](failing).ToSlice()
+	_, columnsErr := FromRowsAuto[int64](multiColumn).ToSlice()
+
+	// assert
+	assert.Equal(t, []dbUser{{ID: 1, Name: "ann"}}, users, "wrong users before the error")
+	var recordErr *RecordError
+	require.ErrorAs(t, scanErr, &recordErr)
+	assert.Equal(t, 2, recordErr.Record, "wrong record")
+	assert.Equal(t, []int64{1}, ids, "wrong ids before the error")
+	assert.ErrorIs(t, failingErr, rowsErr, "wrong rows error")
+	assert.ErrorContains(t, columnsErr, "strm: scanning 2 columns into int64, expected a single one", "wrong error")
+	assert.True(t, closed(), "rows should be closed")
+}
+
+/*
+ * A fake database/sql driver, answering each query with the next of its results
+ */
+
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+	err     error
+}
+
+type fakeDriver struct {
+	mu      sync.Mutex
+	results []fakeResult
+	open    int
+}
+
+var registerFakeDriver sync.Once
+var fakeDrivers sync.Map
+
+// opens a database answering each query with the given results in turn, or by the last one;
+// the returned function reports whether all the rows of the queries have been closed
+func openFakeDB(t *testing.T, results ...fakeResult) (*sql.DB, func() bool) {
+	registerFakeDriver.Do(func() { sql.Register("strmfake", fakeConnector{}) })
+	d := &fakeDriver{results: results}
+	fakeDrivers.Store(t.Name(), d)
+	db, err := sql.Open("strmfake", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return d.open == 0
+	}
+}
+
+type fakeConnector struct{}
+
+func (fakeConnector) Open(name string) (driver.Conn, error) {
+	d, _ := fakeDrivers.Load(name)
+	return &fakeConn{d.(*fakeDriver)}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return &fakeStmt{c.driver}, nil }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	driver *fakeDriver
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	d := s.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	result := d.results[0]
+	if len(d.results) > 1 {
+		d.results = d.results[1:]
+	}
+	d.open++
+	return &fakeRows{fakeResult: result, driver: d}, nil
+}
+
+type fakeRows struct {
+	fakeResult
+	driver *fakeDriver
+	next   int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error {
+	r.driver.mu.Lock()
+	defer r.driver.mu.Unlock()
+	r.driver.open--
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next == len(r.rows) {
+		if r.err != nil {
+			return r.err
+		}
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
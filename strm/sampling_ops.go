@@ -0,0 +1,148 @@
+package strm
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+)
+
+// Shuffled Shuffles the elements order of this Stream in place, with the Fisher-Yates algorithm driven by the given
+// [rng]. A seeded rng produces reproducible shuffles; a nil rng uses a randomly seeded one.
+func (s *Stream[T]) Shuffled(rng *rand.Rand) *Stream[T] {
+	defer s.exit(s.enter("Shuffled", false))
+	rng = orRandom(rng)
+	s.filteredSlice()
+	s.own()
+	rng.Shuffle(len(s.slice), func(i, j int) { s.slice[i], s.slice[j] = s.slice[j], s.slice[i] })
+	return s
+}
+
+// Sample Returns this Stream containing [n] elements picked uniformly at random, or all elements if the Stream has
+// less than [n] elements. Uses single-pass reservoir sampling driven by the given [rng]; a nil rng uses a randomly
+// seeded one. The order of the sampled elements is unspecified. [n] must not be negative.
+func (s *Stream[T]) Sample(n int, rng *rand.Rand) *Stream[T] {
+	defer s.exit(s.enter("Sample", false))
+	validateSampleSize(n)
+	rng = orRandom(rng)
+	if len(s.filteredSlice()) <= n {
+		return s
+	}
+	// the first n positions of the backing slice act as reservoir
+	s.own()
+	for i := n; i < len(s.slice); i++ {
+		if j := rng.IntN(i + 1); j < n {
+			s.slice[j] = s.slice[i]
+		}
+	}
+	return s.Take(n)
+}
+
+// ReservoirSample Pulls every element of this LazyStream, returning [n] of them picked uniformly at random, or all
+// of them if the LazyStream has less than [n] elements, together with the first error of the source. Uses single-pass
+// reservoir sampling driven by the given [rng], holding only [n] elements in memory; a nil rng uses a randomly seeded
+// one. The order of the sampled elements is unspecified. [n] must not be negative.
+func (s *LazyStream[T]) ReservoirSample(n int, rng *rand.Rand) ([]T, error) {
+	s.use("ReservoirSample", true)
+	defer s.src.finish()
+	validateSampleSize(n)
+	rng = orRandom(rng)
+	reservoir := make([]T, 0, min(n, 1024))
+	i := 0
+	for elem := range s.seq {
+		if i < n {
+			reservoir = append(reservoir, elem)
+		} else if j := rng.IntN(i + 1); j < n {
+			reservoir[j] = elem
+		}
+		i++
+	}
+	return reservoir, s.src.error()
+}
+
+// SampleFraction Returns a Stream containing each element with probability [p], driven by the given [rng];
+// a nil rng uses a randomly seeded one. Like Filter, this operation is lazy.
+func (s *Stream[T]) SampleFraction(p float64, rng *rand.Rand) *Stream[T] {
+	defer s.exit(s.enter("SampleFraction", false))
+	rng = orRandom(rng)
+	return s.Filter(func(T) bool { return rng.Float64() < p })
+}
+
+// WeightedSample Returns a new Stream containing [n] elements picked at random, each one with a probability
+// proportional to the weight produced by the given [weightSelector]. Elements with non-positive weights are never
+// picked. Uses single-pass weighted reservoir sampling (A-Res) driven by the given [rng]; a nil rng uses a randomly
+// seeded one. The sampled elements are ordered from the highest weighted random key. [n] must not be negative.
+func (s *Stream[T]) WeightedSample(n int, weightSelector func(T) float64, rng *rand.Rand) *Stream[T] {
+	defer s.exit(s.enter("WeightedSample", true))
+	validateSampleSize(n)
+	rng = orRandom(rng)
+	reservoir := make(weightedHeap[T], 0, n)
+
+	for _, elem := range s.filteredSlice() {
+		weight := weightSelector(elem)
+		if weight <= 0 {
+			continue
+		}
+		key := math.Pow(rng.Float64(), 1/weight)
+		if len(reservoir) < n {
+			heap.Push(&reservoir, weightedElem[T]{elem, key})
+		} else if n > 0 && key > reservoir[0].key {
+			reservoir[0] = weightedElem[T]{elem, key}
+			heap.Fix(&reservoir, 0)
+		}
+	}
+
+	sampled := make([]T, len(reservoir))
+	for i := len(reservoir) - 1; i >= 0; i-- {
+		sampled[i] = heap.Pop(&reservoir).(weightedElem[T]).elem
+	}
+	return derive(s, sampled)
+}
+
+// RandomElement Returns an element of this Stream picked uniformly at random by the given [rng], and false if this
+// Stream is empty. A nil rng uses a randomly seeded one.
+func (s *Stream[T]) RandomElement(rng *rand.Rand) (t T, ok bool) {
+	defer s.exit(s.enter("RandomElement", true))
+	if len(s.filteredSlice()) == 0 {
+		return
+	}
+	return s.slice[orRandom(rng).IntN(len(s.slice))], true
+}
+
+/*
+ * Internal Ops
+ */
+
+// returns the given rng, or a randomly seeded one if nil
+func orRandom(rng *rand.Rand) *rand.Rand {
+	if rng == nil {
+		return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	return rng
+}
+
+// panics if the given sample size [n] is negative
+func validateSampleSize(n int) {
+	if n < 0 {
+		panic("strm: sample size must not be negative")
+	}
+}
+
+// an element and its weighted random key, as kept by WeightedSample
+type weightedElem[T any] struct {
+	elem T
+	key  float64
+}
+
+// a min-heap of weighted elements, by key
+type weightedHeap[T any] []weightedElem[T]
+
+func (wh weightedHeap[T]) Len() int           { return len(wh) }
+func (wh weightedHeap[T]) Less(i, j int) bool { return wh[i].key < wh[j].key }
+func (wh weightedHeap[T]) Swap(i, j int)      { wh[i], wh[j] = wh[j], wh[i] }
+func (wh *weightedHeap[T]) Push(x any)        { *wh = append(*wh, x.(weightedElem[T])) }
+func (wh *weightedHeap[T]) Pop() any {
+	old := *wh
+	last := old[len(old)-1]
+	*wh = old[:len(old)-1]
+	return last
+}
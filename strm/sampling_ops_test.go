@@ -0,0 +1,138 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func seededRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+func TestShuffled(t *testing.T) {
+	// call
+	shuffled := Range(1, 20).Shuffled(seededRand()).ToSlice()
+	sameSeed := Range(1, 20).Shuffled(seededRand()).ToSlice()
+
+	// assert
+	assert.Equal(t, sameSeed, shuffled, "same seed should shuffle the same way")
+	assert.NotEqual(t, Range(1, 20).ToSlice(), shuffled, "elements weren't shuffled")
+	assert.ElementsMatch(t, Range(1, 20).ToSlice(), shuffled, "wrong elements")
+}
+
+func TestSample(t *testing.T) {
+	// call
+	sample := Range(1, 1000).Sample(10, seededRand()).ToSlice()
+	sameSeed := Range(1, 1000).Sample(10, seededRand()).ToSlice()
+	all := Of(1, 2).Sample(10, nil).ToSlice()
+
+	// assert
+	assert.Equal(t, 10, len(sample), "wrong length")
+	assert.Equal(t, sameSeed, sample, "same seed should sample the same elements")
+	assert.Equal(t, 10, Of(sample...).Distinct().Count(), "elements sampled twice")
+	assert.Equal(t, []int{1, 2}, all, "wrong sample")
+}
+
+func TestSampleUniformity(t *testing.T) {
+	// prepare
+	rng := seededRand()
+	hits := make([]int, 10)
+
+	// call
+	for i := 0; i < 10000; i++ {
+		Range(0, 9).Sample(1, rng).ForEach(func(n int) { hits[n]++ })
+	}
+
+	// assert
+	From(hits).ForEach(func(h int) { assert.InDelta(t, 1000, h, 150, "non uniform sampling") })
+}
+
+func TestSampleNegativeSize(t *testing.T) {
+	// assert
+	assert.PanicsWithValue(t, "strm: sample size must not be negative", func() { Range(1, 10).Sample(-1, nil) })
+	assert.PanicsWithValue(t, "strm: sample size must not be negative", func() {
+		_, _ = FromSeq(slices.Values([]int{1})).ReservoirSample(-1, nil)
+	})
+	assert.PanicsWithValue(t, "strm: sample size must not be negative", func() {
+		Range(1, 10).WeightedSample(-1, func(n int) float64 { return float64(n) }, nil)
+	})
+	assert.Empty(t, Range(1, 10).Sample(0, nil).ToSlice(), "wrong sample")
+	assert.Empty(t, Range(1, 10).WeightedSample(0, func(n int) float64 { return float64(n) }, nil).ToSlice(), "wrong sample")
+}
+
+func TestReservoirSample(t *testing.T) {
+	// prepare
+	lazyRange := func(n int) *LazyStream[int] { return FromSeq(slices.Values(Range(1, n).ToSlice())) }
+
+	// call
+	sample, err := lazyRange(1000).ReservoirSample(10, seededRand())
+	sameSeed, _ := lazyRange(1000).ReservoirSample(10, seededRand())
+	all, _ := lazyRange(2).ReservoirSample(10, nil)
+	hits := make([]int, 10)
+	rng := seededRand()
+	for i := 0; i < 10000; i++ {
+		picked, _ := FromSeq(slices.Values(Range(0, 9).ToSlice())).ReservoirSample(1, rng)
+		hits[picked[0]]++
+	}
+
+	// assert
+	require.NoError(t, err)
+	assert.Len(t, sample, 10, "wrong length")
+	assert.Equal(t, sameSeed, sample, "same seed should sample the same elements")
+	assert.Equal(t, 10, Of(sample...).Distinct().Count(), "elements sampled twice")
+	assert.Equal(t, []int{1, 2}, all, "wrong sample")
+	From(hits).ForEach(func(h int) { assert.InDelta(t, 1000, h, 150, "non uniform sampling") })
+}
+
+func TestSampleFraction(t *testing.T) {
+	// call
+	count := Range(1, 10000).SampleFraction(0.1, seededRand()).Count()
+	none := Range(1, 100).SampleFraction(0, nil).Count()
+
+	// assert
+	assert.InDelta(t, 1000, count, 100, "wrong sample size")
+	assert.Equal(t, 0, none, "wrong sample size")
+}
+
+func TestWeightedSample(t *testing.T) {
+	// prepare
+	rng := seededRand()
+	heavyHits := 0
+
+	// call
+	for i := 0; i < 1000; i++ {
+		sample := Of("light", "heavy", "never").
+			WeightedSample(1, func(s string) float64 {
+				switch s {
+				case "heavy":
+					return 9
+				case "light":
+					return 1
+				}
+				return 0
+			}, rng).
+			ToSlice()
+		if sample[0] == "heavy" {
+			heavyHits++
+		}
+	}
+	all := Of(1, 2, 3).WeightedSample(5, func(int) float64 { return 1 }, rng).ToSlice()
+
+	// assert
+	assert.InDelta(t, 900, heavyHits, 50, "wrong weighted sampling")
+	assert.ElementsMatch(t, []int{1, 2, 3}, all, "wrong sample")
+}
+
+func TestRandomElement(t *testing.T) {
+	// call
+	elem, ok := Of("a", "b", "c").RandomElement(seededRand())
+	_, emptyOk := Of[string]().RandomElement(seededRand())
+
+	// assert
+	assert.True(t, ok, "missing element")
+	assert.Contains(t, []string{"a", "b", "c"}, elem, "wrong element")
+	assert.False(t, emptyOk, "empty Stream has no elements")
+}
@@ -0,0 +1,527 @@
+package strm
+
+import (
+	"encoding/binary"
+	"errors"
+	"golang.org/x/exp/slices"
+	"math"
+	"math/bits"
+	"reflect"
+	"sync/atomic"
+)
+
+// ErrIncompatibleSketch Returned when merging sketches built with different parameters
+var ErrIncompatibleSketch = errors.New("strm: incompatible sketch parameters")
+
+// the FNV-1a offset basis and prime: comparable elements are hashed the same way by every process, so sketches
+// can be persisted and merged across processes
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+/*
+ * Stream Terminal Ops
+ */
+
+// ApproxCountDistinct Returns a HyperLogLog sketch of the elements of this Stream, estimating its number of distinct
+// elements with Count() in constant memory. See NewHyperLogLog for the [precision] meaning.
+// Elements are hashed like Distinct does, so non-comparable types are supported.
+func (s *Stream[T]) ApproxCountDistinct(precision uint8) *HyperLogLog[T] {
+	defer s.exit(s.enter("ApproxCountDistinct", true))
+	hll := NewHyperLogLog[T](precision)
+	for _, elem := range s.filteredSlice() {
+		hll.Add(elem)
+	}
+	return hll
+}
+
+// ApproxFrequencies Returns a Count-Min sketch of the elements of this Stream, estimating the number of occurrences
+// of any element with Estimate() in constant memory. See NewCountMinSketch for the [epsilon] and [delta] meaning.
+// Elements are hashed like Distinct does, so non-comparable types are supported.
+func (s *Stream[T]) ApproxFrequencies(epsilon float64, delta float64) *CountMinSketch[T] {
+	defer s.exit(s.enter("ApproxFrequencies", true))
+	cms := NewCountMinSketch[T](epsilon, delta)
+	for _, elem := range s.filteredSlice() {
+		cms.Add(elem)
+	}
+	return cms
+}
+
+// ToBloomFilter Returns a BloomFilter containing all elements of this Stream.
+// See NewBloomFilter for the [expectedItems] and [falsePositiveRate] meaning.
+// Elements are hashed like Distinct does, so non-comparable types are supported.
+func (s *Stream[T]) ToBloomFilter(expectedItems int, falsePositiveRate float64) *BloomFilter[T] {
+	defer s.exit(s.enter("ToBloomFilter", true))
+	bloom := NewBloomFilter[T](expectedItems, falsePositiveRate)
+	for _, elem := range s.filteredSlice() {
+		bloom.Add(elem)
+	}
+	return bloom
+}
+
+// ApproxQuantiles Returns a KLL sketch of the elements of this NumStream, estimating any quantile with Quantile()
+// in sub-linear memory. See NewKLLSketch for the [k] meaning.
+func (s *NumStream[N]) ApproxQuantiles(k int) *KLLSketch[N] {
+	defer s.exit(s.enter("ApproxQuantiles", true))
+	kll := NewKLLSketch[N](k)
+	for _, elem := range s.filteredSlice() {
+		kll.Add(elem)
+	}
+	return kll
+}
+
+/*
+ * HyperLogLog
+ */
+
+// HyperLogLog A sketch estimating the number of distinct elements added to it
+type HyperLogLog[T any] struct {
+	elemHasher[T]
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog Creates a new empty HyperLogLog sketch with 2^[precision] registers.
+// [precision] is clamped within 4 and 16; the standard error of the estimations is about 1.04/sqrt(2^precision),
+// e.g. 0.8% for the precision 14, using 16KB.
+func NewHyperLogLog[T any](precision uint8) *HyperLogLog[T] {
+	precision = min(max(precision, 4), 16)
+	return &HyperLogLog[T]{
+		elemHasher: newElemHasher[T](),
+		precision:  precision,
+		registers:  make([]uint8, 1<<precision),
+	}
+}
+
+// Add Adds the given element to this sketch
+func (hll *HyperLogLog[T]) Add(elem T) {
+	hash := hll.hash(elem)
+	idx := hash >> (64 - hll.precision)
+	// the sentinel bit bounds the rank to 64-precision+1
+	rank := uint8(bits.LeadingZeros64(hash<<hll.precision|1<<(hll.precision-1))) + 1
+	if rank > hll.registers[idx] {
+		hll.registers[idx] = rank
+	}
+}
+
+// Count Returns the estimated number of distinct elements added to this sketch
+func (hll *HyperLogLog[T]) Count() uint64 {
+	m := float64(len(hll.registers))
+	sum, zeros := 0.0, 0
+	for _, register := range hll.registers {
+		sum += 1 / float64(uint64(1)<<register)
+		if register == 0 {
+			zeros++
+		}
+	}
+	estimate := hllAlpha(m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// small range correction: linear counting
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// returns the bias correction constant of HyperLogLog for [m] registers
+func hllAlpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/m)
+}
+
+// Merge Merges the given sketch into this one, which then estimates the distinct elements added to both.
+// Returns ErrIncompatibleSketch if both sketches don't share the same precision.
+func (hll *HyperLogLog[T]) Merge(other *HyperLogLog[T]) error {
+	if hll.precision != other.precision {
+		return ErrIncompatibleSketch
+	}
+	for i, register := range other.registers {
+		if register > hll.registers[i] {
+			hll.registers[i] = register
+		}
+	}
+	return nil
+}
+
+/*
+ * Count-Min Sketch
+ */
+
+// CountMinSketch A sketch estimating the number of occurrences of the elements added to it.
+// Estimations never undercount, and overcount by at most epsilon*N with probability 1-delta,
+// N being the number of added elements.
+type CountMinSketch[T any] struct {
+	elemHasher[T]
+	width    uint64
+	counters [][]uint64
+}
+
+// NewCountMinSketch Creates a new empty Count-Min sketch with an error factor [epsilon] and a [delta] probability
+// of exceeding it, e.g. 0.001 and 0.01. Both must be within 0 and 1 (exclusive).
+func NewCountMinSketch[T any](epsilon float64, delta float64) *CountMinSketch[T] {
+	if !(epsilon > 0 && epsilon < 1 && delta > 0 && delta < 1) {
+		panic("strm: Count-Min sketch epsilon and delta must be within 0 and 1")
+	}
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := int(math.Ceil(math.Log(1 / delta)))
+	counters := make([][]uint64, max(depth, 1))
+	for i := range counters {
+		counters[i] = make([]uint64, width)
+	}
+	return &CountMinSketch[T]{elemHasher: newElemHasher[T](), width: width, counters: counters}
+}
+
+// Add Adds an occurrence of the given element to this sketch
+func (cms *CountMinSketch[T]) Add(elem T) {
+	h1, h2 := splitHash(cms.hash(elem))
+	for i, row := range cms.counters {
+		row[(h1+uint64(i)*h2)%cms.width]++
+	}
+}
+
+// Estimate Returns the estimated number of occurrences of the given element
+func (cms *CountMinSketch[T]) Estimate(elem T) uint64 {
+	h1, h2 := splitHash(cms.hash(elem))
+	estimate := uint64(math.MaxUint64)
+	for i, row := range cms.counters {
+		estimate = min(estimate, row[(h1+uint64(i)*h2)%cms.width])
+	}
+	return estimate
+}
+
+// Merge Merges the given sketch into this one, which then estimates the occurrences added to both.
+// Returns ErrIncompatibleSketch if both sketches weren't created with the same epsilon and delta.
+func (cms *CountMinSketch[T]) Merge(other *CountMinSketch[T]) error {
+	if cms.width != other.width || len(cms.counters) != len(other.counters) {
+		return ErrIncompatibleSketch
+	}
+	for i, row := range other.counters {
+		for j, count := range row {
+			cms.counters[i][j] += count
+		}
+	}
+	return nil
+}
+
+/*
+ * Bloom Filter
+ */
+
+// BloomFilter A set membership filter answering whether an element was added, with no false negatives
+// and a bounded rate of false positives
+type BloomFilter[T any] struct {
+	elemHasher[T]
+	hashes uint64
+	bits   []uint64
+}
+
+// NewBloomFilter Creates a new empty BloomFilter sized for holding [expectedItems] elements
+// with the given [falsePositiveRate], e.g. 0.01, within 0 and 1 (exclusive)
+func NewBloomFilter[T any](expectedItems int, falsePositiveRate float64) *BloomFilter[T] {
+	if !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		panic("strm: Bloom filter false positive rate must be within 0 and 1")
+	}
+	n := float64(max(expectedItems, 1))
+	nBits := max(math.Ceil(-n*math.Log(falsePositiveRate)/(math.Ln2*math.Ln2)), 64)
+	hashes := max(math.Round(nBits/n*math.Ln2), 1)
+	return &BloomFilter[T]{
+		elemHasher: newElemHasher[T](),
+		hashes:     uint64(hashes),
+		bits:       make([]uint64, (uint64(nBits)+63)/64),
+	}
+}
+
+// Add Adds the given element to this filter
+func (bf *BloomFilter[T]) Add(elem T) {
+	h1, h2 := splitHash(bf.hash(elem))
+	nBits := uint64(len(bf.bits)) * 64
+	for i := uint64(0); i < bf.hashes; i++ {
+		bit := (h1 + i*h2) % nBits
+		bf.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// MightContain Returns false if the given element was never added to this filter,
+// true if it was added or, with the configured probability, if it's a false positive
+func (bf *BloomFilter[T]) MightContain(elem T) bool {
+	h1, h2 := splitHash(bf.hash(elem))
+	nBits := uint64(len(bf.bits)) * 64
+	for i := uint64(0); i < bf.hashes; i++ {
+		bit := (h1 + i*h2) % nBits
+		if bf.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge Merges the given filter into this one, which then contains the elements added to both.
+// Returns ErrIncompatibleSketch if both filters weren't created with the same parameters.
+func (bf *BloomFilter[T]) Merge(other *BloomFilter[T]) error {
+	if bf.hashes != other.hashes || len(bf.bits) != len(other.bits) {
+		return ErrIncompatibleSketch
+	}
+	for i, word := range other.bits {
+		bf.bits[i] |= word
+	}
+	return nil
+}
+
+/*
+ * KLL Sketch
+ */
+
+// KLLSketch A sketch estimating the quantiles of the numbers added to it, within a rank error of about 1.7/k
+type KLLSketch[N Number] struct {
+	k          int
+	count      int
+	compactors [][]N
+	coin       uint64
+}
+
+// NewKLLSketch Creates a new empty KLL sketch. Its accuracy and memory grow with [k], e.g. 200 for about 1% error.
+func NewKLLSketch[N Number](k int) *KLLSketch[N] {
+	return &KLLSketch[N]{k: max(k, 8), compactors: make([][]N, 1), coin: 0x9E3779B97F4A7C15}
+}
+
+// Add Adds the given number to this sketch
+func (kll *KLLSketch[N]) Add(elem N) {
+	kll.compactors[0] = append(kll.compactors[0], elem)
+	kll.count++
+	kll.compress()
+}
+
+// Count Returns the number of elements added to this sketch
+func (kll *KLLSketch[N]) Count() int {
+	return kll.count
+}
+
+// Quantile Returns the estimated [q] quantile (within 0 and 1) of the numbers added to this sketch,
+// or 0 if it's empty
+func (kll *KLLSketch[N]) Quantile(q float64) N {
+	type weighted struct {
+		value  N
+		weight int
+	}
+	var items []weighted
+	for level, compactor := range kll.compactors {
+		for _, elem := range compactor {
+			items = append(items, weighted{elem, 1 << level})
+		}
+	}
+	if len(items) == 0 {
+		return 0
+	}
+	slices.SortFunc(items, func(a, b weighted) int {
+		switch {
+		case a.value < b.value:
+			return -1
+		case a.value > b.value:
+			return 1
+		}
+		return 0
+	})
+
+	target := q * float64(kll.count)
+	cumulative := 0
+	for _, item := range items {
+		cumulative += item.weight
+		if float64(cumulative) >= target {
+			return item.value
+		}
+	}
+	return items[len(items)-1].value
+}
+
+// Merge Merges the given sketch into this one, which then estimates the quantiles of the numbers added to both.
+// Returns ErrIncompatibleSketch if both sketches weren't created with the same k.
+func (kll *KLLSketch[N]) Merge(other *KLLSketch[N]) error {
+	if kll.k != other.k {
+		return ErrIncompatibleSketch
+	}
+	for level, compactor := range other.compactors {
+		if level == len(kll.compactors) {
+			kll.compactors = append(kll.compactors, nil)
+		}
+		kll.compactors[level] = append(kll.compactors[level], compactor...)
+	}
+	kll.count += other.count
+	kll.compress()
+	return nil
+}
+
+// returns the capacity of the given compactor level, geometrically decreasing from the top level
+func (kll *KLLSketch[N]) capacity(level int) int {
+	depth := len(kll.compactors) - level - 1
+	return max(int(math.Ceil(float64(kll.k)*math.Pow(2.0/3.0, float64(depth)))), 2)
+}
+
+// compacts the lowest full compactor levels, promoting half of their elements to the next level
+func (kll *KLLSketch[N]) compress() {
+	for level := 0; level < len(kll.compactors); level++ {
+		if len(kll.compactors[level]) < kll.capacity(level) {
+			continue
+		}
+		if level+1 == len(kll.compactors) {
+			kll.compactors = append(kll.compactors, nil)
+		}
+		compactor := kll.compactors[level]
+		slices.Sort(compactor)
+		// deterministic xorshift coin flip choosing the promoted half
+		kll.coin ^= kll.coin << 13
+		kll.coin ^= kll.coin >> 7
+		kll.coin ^= kll.coin << 17
+		for i := int(kll.coin & 1); i < len(compactor); i += 2 {
+			kll.compactors[level+1] = append(kll.compactors[level+1], compactor[i])
+		}
+		kll.compactors[level] = compactor[:0]
+	}
+}
+
+/*
+ * Internal Ops
+ */
+
+// hashes elements of a sketch following the same path as Stream.calculateHash
+type elemHasher[T any] struct {
+	comparable bool
+	hasher     bool
+}
+
+func newElemHasher[T any]() elemHasher[T] {
+	return elemHasher[T]{comparable: isComparableType[T](), hasher: isHasherType[T]()}
+}
+
+// calculates a well distributed 64-bit hash for the given generic value, equal for the elements Distinct considers
+// equal: the hash key of Stream.hashKey is mixed, or hashed when it's the comparable element itself
+func (eh elemHasher[T]) hash(elem T) uint64 {
+	key, err := hashKeyOf(elem, eh.comparable, eh.hasher)
+	if err != nil {
+		// best effort: like Stream.calculateHash, the element is distinct from all others
+		return mix64(unhashable.Add(1))
+	}
+	if eh.hasher || !eh.comparable {
+		return mix64(key.(uint64))
+	}
+	return hashComparable(key)
+}
+
+// counts the elements which couldn't be hashed
+var unhashable atomic.Uint64
+
+// hashes the given comparable key, equal keys having equal hashes
+func hashComparable(key any) uint64 {
+	switch v := key.(type) {
+	case string:
+		hash := fnv1a(fnvOffset64)
+		hash.writeString(v)
+		return mix64(uint64(hash))
+	case int:
+		return mix64(uint64(v))
+	case int64:
+		return mix64(uint64(v))
+	case int32:
+		return mix64(uint64(v))
+	case uint:
+		return mix64(uint64(v))
+	case uint64:
+		return mix64(v)
+	case uint32:
+		return mix64(uint64(v))
+	case float64:
+		return mix64(math.Float64bits(v + 0)) // -0 == +0
+	case float32:
+		return mix64(math.Float64bits(float64(v) + 0))
+	case bool:
+		if v {
+			return mix64(1)
+		}
+		return mix64(0)
+	}
+	// other comparable types, e.g. structs or named types, hashed by value
+	hash := fnv1a(fnvOffset64)
+	writeComparable(&hash, reflect.ValueOf(key))
+	return mix64(uint64(hash))
+}
+
+// a 64-bit FNV-1a hash, deterministic unlike maphash, and allocation free unlike hash/fnv
+type fnv1a uint64
+
+func (h *fnv1a) write(p []byte) {
+	for _, b := range p {
+		*h = (*h ^ fnv1a(b)) * fnvPrime64
+	}
+}
+
+func (h *fnv1a) writeString(s string) {
+	for i := 0; i < len(s); i++ {
+		*h = (*h ^ fnv1a(s[i])) * fnvPrime64
+	}
+}
+
+// writes the given comparable value to the given [hash], as compared by ==: pointers and channels by address,
+// interfaces by their dynamic value, arrays and structs by their elements and fields, unexported ones included
+func writeComparable(hash *fnv1a, v reflect.Value) {
+	var buf [8]byte
+	writeUint := func(x uint64) {
+		binary.LittleEndian.PutUint64(buf[:], x)
+		hash.write(buf[:])
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(math.Float64bits(v.Float() + 0)) // -0 == +0
+	case reflect.Complex64, reflect.Complex128:
+		writeUint(math.Float64bits(real(v.Complex()) + 0))
+		writeUint(math.Float64bits(imag(v.Complex()) + 0))
+	case reflect.String:
+		// length-prefixed, so that adjacent string fields can't be shifted into one another
+		writeUint(uint64(v.Len()))
+		hash.writeString(v.String())
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		writeUint(uint64(v.Pointer()))
+	case reflect.Interface:
+		if !v.IsNil() {
+			writeComparable(hash, v.Elem())
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			writeComparable(hash, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			writeComparable(hash, v.Field(i))
+		}
+	}
+}
+
+// splits the given hash in two 64-bit hashes for double hashing, the second one being odd
+func splitHash(hash uint64) (uint64, uint64) {
+	return hash, mix64(hash^0x9E3779B97F4A7C15) | 1
+}
+
+// the splitmix64 finalizer, spreading the bits of poorly distributed hashes like small Hasher ids
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,195 @@
+package strm
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestApproxCountDistinct(t *testing.T) {
+	// prepare
+	var ids []int
+	for i := 0; i < 100000; i++ {
+		ids = append(ids, i%20000)
+	}
+
+	// call
+	count := From(ids).ApproxCountDistinct(14).Count()
+	small := Of("a", "b", "a").ApproxCountDistinct(14).Count()
+	slices := Of([]int{1}, []int{1}, []int{2}).ApproxCountDistinct(14).Count()
+
+	// assert
+	assert.InEpsilon(t, 20000, count, 0.03, "wrong estimation")
+	assert.Equal(t, uint64(2), small, "wrong estimation")
+	assert.Equal(t, uint64(2), slices, "wrong estimation")
+}
+
+func TestHyperLogLogParallelMerge(t *testing.T) {
+	// prepare
+	chunks := Range(1, 50000).Chunked(10000)
+	sketches := make([]*HyperLogLog[int], len(chunks))
+	var wg sync.WaitGroup
+
+	// call
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sketches[i] = From(chunk).ApproxCountDistinct(12)
+		}()
+	}
+	wg.Wait()
+	for _, sketch := range sketches[1:] {
+		require.NoError(t, sketches[0].Merge(sketch))
+	}
+
+	// assert
+	assert.InEpsilon(t, 50000, sketches[0].Count(), 0.05, "wrong estimation")
+	assert.ErrorIs(t, sketches[0].Merge(NewHyperLogLog[int](10)), ErrIncompatibleSketch)
+}
+
+func TestApproxFrequencies(t *testing.T) {
+	// prepare
+	var events []string
+	for i := 0; i < 10000; i++ {
+		events = append(events, fmt.Sprint("event-", i%100))
+	}
+	events = append(events, "rare")
+
+	// call
+	cms := From(events).ApproxFrequencies(0.001, 0.01)
+	other := Of("rare", "rare").ApproxFrequencies(0.001, 0.01)
+	require.NoError(t, cms.Merge(other))
+
+	// assert
+	assert.GreaterOrEqual(t, cms.Estimate("event-7"), uint64(100), "estimations never undercount")
+	assert.InDelta(t, 100, cms.Estimate("event-7"), 10, "wrong estimation")
+	assert.InDelta(t, 3, cms.Estimate("rare"), 10, "wrong estimation")
+	assert.ErrorIs(t, cms.Merge(NewCountMinSketch[string](0.1, 0.01)), ErrIncompatibleSketch)
+}
+
+func TestToBloomFilter(t *testing.T) {
+	// call
+	bloom := Range(0, 999).ToStrm().ToBloomFilter(1000, 0.01)
+	people := Of(Person{"Tim", 30}, Person{"Bil", 40}).ToBloomFilter(10, 0.01)
+
+	falsePositives := 0
+	for i := 1000; i < 11000; i++ {
+		if bloom.MightContain(i) {
+			falsePositives++
+		}
+	}
+
+	// assert: the hashing being deterministic, so are the false positives
+	assert.True(t, Range(0, 999).All(bloom.MightContain), "bloom filters have no false negatives")
+	assert.Less(t, falsePositives, 200, "too many false positives")
+	assert.True(t, people.MightContain(Person{"Tim", 30}), "missing element")
+	assert.False(t, people.MightContain(Person{"Tim", 31}), "unexpected element")
+}
+
+func TestSketchHashIsStable(t *testing.T) {
+	// call
+	stringHash := newElemHasher[string]().hash("strm")
+	structHash := newElemHasher[Person]().hash(Person{"Tim", 30})
+
+	// assert: the hashes don't depend on the process, so sketches can be persisted and merged across processes
+	assert.Equal(t, uint64(9025822084118631551), stringHash, "wrong string hash")
+	assert.Equal(t, uint64(11225353825786082691), structHash, "wrong struct hash")
+}
+
+func TestBloomFilterMerge(t *testing.T) {
+	// prepare
+	bloom := Of("a").ToBloomFilter(100, 0.01)
+
+	// call
+	err := bloom.Merge(Of("b").ToBloomFilter(100, 0.01))
+
+	// assert
+	require.NoError(t, err)
+	assert.True(t, bloom.MightContain("a"), "missing element")
+	assert.True(t, bloom.MightContain("b"), "missing element")
+	assert.ErrorIs(t, bloom.Merge(NewBloomFilter[string](10000, 0.01)), ErrIncompatibleSketch)
+}
+
+func TestApproxQuantiles(t *testing.T) {
+	// call
+	kll := NumRange(1.0, 100000, 1).ApproxQuantiles(200)
+	empty := NumsOf[int]().ApproxQuantiles(200)
+
+	// assert
+	assert.Equal(t, 100000, kll.Count(), "wrong count")
+	assert.InDelta(t, 50000, kll.Quantile(0.5), 2000, "wrong median")
+	assert.InDelta(t, 99000, kll.Quantile(0.99), 2000, "wrong p99")
+	assert.Equal(t, 0, empty.Quantile(0.5), "wrong quantile")
+}
+
+func TestKLLSketchMerge(t *testing.T) {
+	// prepare
+	low := NumRange(1, 50000, 1).ApproxQuantiles(200)
+	high := NumRange(50001, 100000, 1).ApproxQuantiles(200)
+
+	// call
+	err := low.Merge(high)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, 100000, low.Count(), "wrong count")
+	assert.InDelta(t, 25000, low.Quantile(0.25), 2000, "wrong quartile")
+	assert.InDelta(t, 75000, low.Quantile(0.75), 2000, "wrong quartile")
+	assert.ErrorIs(t, low.Merge(NewKLLSketch[int](100)), ErrIncompatibleSketch)
+}
+
+func TestSketchesHashLikeDistinct(t *testing.T) {
+	// prepare
+	type point struct {
+		x, y float64
+		tag  *string
+	}
+	tag := "a"
+	points := []point{{0, 1, &tag}, {math.Copysign(0, -1), 1, &tag}, {0, 1, nil}, {1, 0, &tag}}
+
+	// call
+	distinct := From(points).Distinct().Count()
+	approx := From(points).ApproxCountDistinct(14).Count()
+	bloom := From(points[:1]).ToBloomFilter(10, 0.01)
+
+	// assert
+	assert.Equal(t, 3, distinct, "wrong distinct count")
+	assert.Equal(t, uint64(distinct), approx, "sketches should compare elements like Distinct")
+	assert.True(t, bloom.MightContain(point{math.Copysign(0, -1), 1, &tag}), "-0 and +0 should be equal")
+	assert.False(t, bloom.MightContain(point{0, 1, nil}), "unexpected element")
+}
+
+func TestSketchesInvalidParameters(t *testing.T) {
+	// assert
+	assert.PanicsWithValue(t, "strm: Bloom filter false positive rate must be within 0 and 1", func() {
+		NewBloomFilter[int](10, 0)
+	})
+	assert.Panics(t, func() { NewBloomFilter[int](10, math.NaN()) })
+	assert.PanicsWithValue(t, "strm: Count-Min sketch epsilon and delta must be within 0 and 1", func() {
+		NewCountMinSketch[int](0, 0.01)
+	})
+	assert.Panics(t, func() { NewCountMinSketch[int](0.01, 1) })
+}
+
+func TestHyperLogLogSmallPrecisions(t *testing.T) {
+	for precision := uint8(4); precision <= 6; precision++ {
+		// prepare
+		m := float64(uint(1) << precision)
+		hll := NewHyperLogLog[int](precision)
+		for i := range 100000 {
+			hll.Add(i)
+		}
+
+		// call
+		count := float64(hll.Count())
+
+		// assert
+		assert.InDelta(t, 100000, count, 100000*4*1.04/math.Sqrt(m), "precision %d: wrong estimate", precision)
+	}
+	assert.Equal(t, 0.673, hllAlpha(16), "wrong alpha")
+	assert.Equal(t, 0.709, hllAlpha(64), "wrong alpha")
+}
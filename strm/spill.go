@@ -0,0 +1,301 @@
+package strm
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/exp/constraints"
+	"io"
+	"iter"
+	"os"
+	"slices"
+)
+
+// SpillOptions The options of the external-memory operations of LazyStreams, sorting up to [MaxElementsInMemory]
+// elements in memory and spilling them to a temporary file when reached, the sorted files being merged back once all
+// the elements are read, [MaxMergeFiles] at a time. Its zero value holds 100000 elements in memory, merges up to 64
+// files at once and spills them to os.TempDir with the GobCodec.
+type SpillOptions[T any] struct {
+	// MaxElementsInMemory the maximum number of elements held in memory, 100000 when zero. The budget is a number of
+	// elements rather than bytes: derive it from the memory available and the size of an element, e.g. 256MB for
+	// elements of about 1KB gives 250000
+	MaxElementsInMemory int
+	// MaxMergeFiles the maximum number of temporary files opened at once, 64 when zero: more files are first merged
+	// into larger ones, in several passes
+	MaxMergeFiles int
+	// Dir the directory of the temporary files, os.TempDir when empty
+	Dir string
+	// Codec the encoding of the elements in the temporary files, GobCodec when nil
+	Codec Codec[T]
+}
+
+// Codec The encoding of the elements spilled to disk by the external-memory operations, see SpillOptions
+type Codec[T any] interface {
+	// NewEncoder Returns a function writing the given elements to [w]
+	NewEncoder(w io.Writer) func(elem T) error
+	// NewDecoder Returns a function reading the elements written to [r] back, returning io.EOF once they're all read
+	NewDecoder(r io.Reader) func() (T, error)
+}
+
+// GobCodec The Codec of the encoding/gob package, only encoding the exported fields of structs
+type GobCodec[T any] struct{}
+
+// NewEncoder Returns a function writing the given elements to [w] with a gob.Encoder
+func (GobCodec[T]) NewEncoder(w io.Writer) func(elem T) error {
+	enc := gob.NewEncoder(w)
+	return func(elem T) error { return enc.Encode(elem) }
+}
+
+// NewDecoder Returns a function reading the elements written to [r] back with a gob.Decoder
+func (GobCodec[T]) NewDecoder(r io.Reader) func() (T, error) {
+	dec := gob.NewDecoder(r)
+	return func() (elem T, err error) {
+		err = dec.Decode(&elem)
+		return
+	}
+}
+
+// JSONCodec The Codec of the encoding/json package, writing an element per line
+type JSONCodec[T any] struct{}
+
+// NewEncoder Returns a function writing the given elements to [w] with a json.Encoder
+func (JSONCodec[T]) NewEncoder(w io.Writer) func(elem T) error {
+	enc := json.NewEncoder(w)
+	return func(elem T) error { return enc.Encode(elem) }
+}
+
+// NewDecoder Returns a function reading the elements written to [r] back with a json.Decoder
+func (JSONCodec[T]) NewDecoder(r io.Reader) func() (T, error) {
+	dec := json.NewDecoder(r)
+	return func() (elem T, err error) {
+		err = dec.Decode(&elem)
+		return
+	}
+}
+
+// Group The elements of a LazyStream sharing the same [Key], see ExternalGroupBy
+type Group[K any, T any] struct {
+	Key      K
+	Elements []T
+}
+
+// ExternalSortedBy Lazily sorts the elements of this LazyStream with the given [cmp] function, as
+// slices.SortStableFunc does, holding at most [opts.MaxElementsInMemory] elements in memory: the others are spilled to
+// temporary files, merged back as the sorted elements are pulled, and removed once the terminal operation ends.
+// Errors of the temporary files stop the LazyStream and are returned by Err.
+func (s *LazyStream[T]) ExternalSortedBy(cmp func(a, b T) int, opts SpillOptions[T]) *LazyStream[T] {
+	s.use("ExternalSortedBy", false)
+	s.seq = spillSorted(s, cmp, opts)
+	return s
+}
+
+// ExternalGroupBy Returns a new LazyStream of the Groups of the elements of the given LazyStream sharing the key
+// produced by the given [keySelector], in ascending key order, sorting them as ExternalSortedBy does.
+// Only the elements of a single Group are held in memory once they're sorted.
+// The given LazyStream is consumed: its source, errors and Close are taken over by the returned one.
+func ExternalGroupBy[T any, K constraints.Ordered](
+	s *LazyStream[T], keySelector func(T) K, opts SpillOptions[T]) *LazyStream[Group[K, T]] {
+	s.handOver("ExternalGroupBy")
+	sorted := spillSorted(s, byKey(keySelector), opts)
+	return &LazyStream[Group[K, T]]{src: s.src, seq: func(yield func(Group[K, T]) bool) {
+		var group *Group[K, T]
+		for elem := range sorted {
+			key := keySelector(elem)
+			if group != nil && cmp.Compare(group.Key, key) == 0 {
+				group.Elements = append(group.Elements, elem)
+				continue
+			}
+			if group != nil && !yield(*group) {
+				return
+			}
+			group = &Group[K, T]{key, []T{elem}}
+		}
+		if group != nil && s.src.error() == nil {
+			yield(*group)
+		}
+	}}
+}
+
+// ExternalDistinctBy Returns a new LazyStream of the elements of the given LazyStream with distinct keys, produced by
+// the given [keySelector], keeping the first element of each key. The elements are returned in ascending key order,
+// sorting them as ExternalSortedBy does.
+// The given LazyStream is consumed: its source, errors and Close are taken over by the returned one.
+func ExternalDistinctBy[T any, K constraints.Ordered](
+	s *LazyStream[T], keySelector func(T) K, opts SpillOptions[T]) *LazyStream[T] {
+	s.handOver("ExternalDistinctBy")
+	return &LazyStream[T]{src: s.src, seq: distinctSorted(spillSorted(s, byKey(keySelector), opts), keySelector)}
+}
+
+// ExternalDistinct Returns a new LazyStream of the distinct elements of the given LazyStream, in ascending order,
+// sorting them as ExternalSortedBy does.
+// The given LazyStream is consumed: its source, errors and Close are taken over by the returned one.
+func ExternalDistinct[T constraints.Ordered](s *LazyStream[T], opts SpillOptions[T]) *LazyStream[T] {
+	s.handOver("ExternalDistinct")
+	identity := func(elem T) T { return elem }
+	return &LazyStream[T]{src: s.src, seq: distinctSorted(spillSorted(s, cmp.Compare[T], opts), identity)}
+}
+
+/*
+ * Internal Ops
+ */
+
+// returns a comparison function of elements by the given [keySelector]
+func byKey[T any, K constraints.Ordered](keySelector func(T) K) func(a, b T) int {
+	return func(a, b T) int { return cmp.Compare(keySelector(a), keySelector(b)) }
+}
+
+// returns the first element of each run of elements with the same key of the given sorted ones
+func distinctSorted[T any, K constraints.Ordered](sorted iter.Seq[T], keySelector func(T) K) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var last K
+		first := true
+		for elem := range sorted {
+			key := keySelector(elem)
+			if !first && cmp.Compare(last, key) == 0 {
+				continue
+			}
+			if !yield(elem) {
+				return
+			}
+			last, first = key, false
+		}
+	}
+}
+
+// returns the elements of the given LazyStream stably sorted with the given [cmp] function, spilling the sorted
+// runs of elements exceeding the memory budget of the given [opts] to temporary files, and merging them back
+func spillSorted[T any](s *LazyStream[T], cmp func(a, b T) int, opts SpillOptions[T]) iter.Seq[T] {
+	maxInMemory, maxFiles, codec := opts.MaxElementsInMemory, opts.MaxMergeFiles, opts.Codec
+	if maxInMemory <= 0 {
+		maxInMemory = 100000
+	}
+	if maxFiles <= 0 {
+		maxFiles = 64
+	}
+	if codec == nil {
+		codec = GobCodec[T]{}
+	}
+	seq := s.seq
+
+	return func(yield func(T) bool) {
+		// the names of the sorted runs, in the order of their elements
+		var runs []string
+		defer func() {
+			for _, run := range runs {
+				_ = os.Remove(run)
+			}
+		}()
+		failed := false
+		fail := func(err error) {
+			failed = true
+			s.src.fail(err)
+		}
+		buffer := make([]T, 0, min(maxInMemory, 1024))
+		for elem := range seq {
+			if buffer = append(buffer, elem); len(buffer) < maxInMemory {
+				continue
+			}
+			slices.SortStableFunc(buffer, cmp)
+			run, err := spill(slices.Values(buffer), opts.Dir, codec)
+			if err != nil {
+				fail(err)
+				return
+			}
+			runs = append(runs, run)
+			clear(buffer)
+			buffer = buffer[:0]
+		}
+		if s.src.error() != nil {
+			return
+		}
+		slices.SortStableFunc(buffer, cmp)
+
+		// the first runs are merged into a single one until the remaining ones and the in-memory one can be merged at once
+		for len(runs) >= maxFiles {
+			merging := make([]iter.Seq[T], maxFiles)
+			for i, run := range runs[:maxFiles] {
+				merging[i] = spilled(run, codec, fail)
+			}
+			run, err := spill(mergeSorted(merging, cmp), opts.Dir, codec)
+			if err == nil && failed {
+				_ = os.Remove(run)
+			}
+			if err != nil {
+				fail(err)
+			}
+			if failed {
+				return
+			}
+			for _, merged := range runs[:maxFiles] {
+				_ = os.Remove(merged)
+			}
+			runs = append([]string{run}, runs[maxFiles:]...)
+		}
+
+		sorted := make([]iter.Seq[T], 0, len(runs)+1)
+		for _, run := range runs {
+			sorted = append(sorted, spilled(run, codec, fail))
+		}
+		sorted = append(sorted, slices.Values(buffer))
+		for elem := range mergeSorted(sorted, cmp) {
+			if failed || !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// writes the given elements to a new temporary file in the given [dir], returning its name
+func spill[T any](elems iter.Seq[T], dir string, codec Codec[T]) (string, error) {
+	file, err := os.CreateTemp(dir, "strm-spill-*")
+	if err != nil {
+		return "", fmt.Errorf("strm: spilling to disk: %w", err)
+	}
+	w := bufio.NewWriter(file)
+	encode := codec.NewEncoder(w)
+	for elem := range elems {
+		if err = encode(elem); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("strm: spilling to disk: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// returns the elements of the spilled file of the given [name], opened while they're read, reporting its errors to
+// the given [fail] function
+func spilled[T any](name string, codec Codec[T], fail func(error)) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		file, err := os.Open(name)
+		if err != nil {
+			fail(fmt.Errorf("strm: reading spilled elements: %w", err))
+			return
+		}
+		defer file.Close()
+		decode := codec.NewDecoder(bufio.NewReader(file))
+		for {
+			elem, err := decode()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				fail(fmt.Errorf("strm: reading spilled elements: %w", err))
+				return
+			}
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package strm
+
+import (
+	"cmp"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type visit struct {
+	User string
+	Page int
+}
+
+func TestExternalSortedBy(t *testing.T) {
+	// prepare
+	dir := t.TempDir()
+	rng := rand.New(rand.NewPCG(1, 2))
+	visits := make([]visit, 1000)
+	for i := range visits {
+		visits[i] = visit{string(rune('a' + rng.IntN(26))), i}
+	}
+	byUser := func(a, b visit) int { return strings.Compare(a.User, b.User) }
+
+	for name, codec := range map[string]Codec[visit]{"gob": GobCodec[visit]{}, "json": JSONCodec[visit]{}} {
+		// call
+		var spilled int
+		sorted, err := FromSeq(slices.Values(visits)).
+			ExternalSortedBy(byUser, SpillOptions[visit]{MaxElementsInMemory: 64, Dir: dir, Codec: codec}).
+			OnEach(func(visit) {
+				if spilled == 0 {
+					entries, _ := os.ReadDir(dir)
+					spilled = len(entries)
+				}
+			}).
+			ToSlice()
+
+		// assert
+		require.NoError(t, err, name)
+		expected := slices.Clone(visits)
+		slices.SortStableFunc(expected, byUser)
+		assert.Equal(t, expected, sorted, "%s: the sort should be stable", name)
+		assert.Equal(t, 15, spilled, "%s: wrong number of spilled runs", name)
+		entries, _ := os.ReadDir(dir)
+		assert.Empty(t, entries, "%s: the temporary files should be removed", name)
+	}
+}
+
+func TestExternalSortedByMultiPassMerge(t *testing.T) {
+	// prepare
+	dir := t.TempDir()
+	rng := rand.New(rand.NewPCG(3, 4))
+	visits := make([]visit, 100)
+	for i := range visits {
+		visits[i] = visit{string(rune('a' + rng.IntN(5))), i}
+	}
+	byUser := func(a, b visit) int { return strings.Compare(a.User, b.User) }
+
+	// call
+	sorted, err := From(visits).Lazy().
+		ExternalSortedBy(byUser, SpillOptions[visit]{MaxElementsInMemory: 4, MaxMergeFiles: 3, Dir: dir}).
+		ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	expected := slices.Clone(visits)
+	slices.SortStableFunc(expected, byUser)
+	assert.Equal(t, expected, sorted, "the sort should be stable across merge passes")
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries, "the temporary files should be removed")
+}
+
+func TestExternalSortedByInMemory(t *testing.T) {
+	// prepare
+	dir := t.TempDir()
+
+	// call
+	sorted, err := FromSeq(slices.Values([]int{5, 3, 1, 4, 2})).
+		ExternalSortedBy(cmp.Compare[int], SpillOptions[int]{MaxElementsInMemory: 10, Dir: dir}).
+		ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, sorted, "wrong sort")
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries, "nothing should be spilled")
+}
+
+func TestExternalSortedByEarlyStop(t *testing.T) {
+	// prepare
+	dir := t.TempDir()
+	s := FromSeq(func(yield func(int) bool) {
+		for i := 100; i > 0 && yield(i); i-- {
+		}
+	})
+
+	// call
+	first, ok := s.ExternalSortedBy(cmp.Compare[int], SpillOptions[int]{MaxElementsInMemory: 10, Dir: dir}).First()
+
+	// assert
+	assert.True(t, ok, "an element should be found")
+	assert.Equal(t, 1, first, "wrong first element")
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries, "the temporary files should be removed")
+}
+
+func TestExternalGroupBy(t *testing.T) {
+	// prepare
+	visits := FromSeq(slices.Values([]visit{{"bob", 1}, {"amy", 2}, {"bob", 3}, {"cid", 4}, {"amy", 5}, {"bob", 6}}))
+
+	// call
+	groups, err := ExternalGroupBy(visits, func(v visit) string { return v.User },
+		SpillOptions[visit]{MaxElementsInMemory: 2, Dir: t.TempDir()}).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []Group[string, visit]{
+		{"amy", []visit{{"amy", 2}, {"amy", 5}}},
+		{"bob", []visit{{"bob", 1}, {"bob", 3}, {"bob", 6}}},
+		{"cid", []visit{{"cid", 4}}},
+	}, groups, "wrong groups")
+}
+
+func TestStreamLazy(t *testing.T) {
+	// prepare
+	s := Of(3, 1, 3, 2)
+
+	// call
+	distinct, err := ExternalDistinct(s.Lazy(), SpillOptions[int]{MaxElementsInMemory: 1, Dir: t.TempDir()}).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, distinct, "wrong distinct elements")
+	assert.Equal(t, StateConsumed, s.State(), "the Stream should be consumed")
+}
+
+func TestExternalDistinct(t *testing.T) {
+	// prepare
+	visits := FromSeq(slices.Values([]visit{{"bob", 1}, {"amy", 2}, {"bob", 3}, {"cid", 4}, {"amy", 5}}))
+	opts := SpillOptions[visit]{MaxElementsInMemory: 2, Dir: t.TempDir()}
+
+	// call
+	firstVisits, err := ExternalDistinctBy(visits, func(v visit) string { return v.User }, opts).ToSlice()
+	distinct, err2 := ExternalDistinct(FromSeq(slices.Values([]int{3, 1, 3, 2, 1, 3})),
+		SpillOptions[int]{MaxElementsInMemory: 2, Dir: t.TempDir()}).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	require.NoError(t, err2)
+	assert.Equal(t, []visit{{"amy", 2}, {"bob", 1}, {"cid", 4}}, firstVisits, "the first element should be kept")
+	assert.Equal(t, []int{1, 2, 3}, distinct, "wrong distinct elements")
+}
+
+// a Codec failing to read its elements back
+type failingCodec struct{ GobCodec[int] }
+
+func (failingCodec) NewDecoder(io.Reader) func() (int, error) {
+	return func() (int, error) { return 0, errors.New("corrupted") }
+}
+
+func TestExternalSortedByCodecError(t *testing.T) {
+	// prepare
+	dir := t.TempDir()
+
+	// call
+	sorted, err := FromSeq(slices.Values([]int{4, 3, 2, 1})).
+		ExternalSortedBy(cmp.Compare[int], SpillOptions[int]{MaxElementsInMemory: 2, Dir: dir, Codec: failingCodec{}}).
+		ToSlice()
+
+	// assert
+	assert.EqualError(t, err, "strm: reading spilled elements: corrupted")
+	assert.Empty(t, sorted, "no elements should be returned")
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries, "the temporary files should be removed")
+}
+
+func TestExternalSortedByMissingDir(t *testing.T) {
+	// call
+	_, err := FromSeq(slices.Values([]int{2, 1})).
+		ExternalSortedBy(cmp.Compare[int], SpillOptions[int]{MaxElementsInMemory: 1, Dir: "/nonexistent/strm"}).
+		ToSlice()
+
+	// assert
+	assert.ErrorIs(t, err, os.ErrNotExist, "wrong error")
+}
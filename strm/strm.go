@@ -19,6 +19,19 @@ type Stream[T any] struct {
 	slice      []T
 	filters    []predicate[T]
 	comparable bool
+	hasher     bool
+	// true while the backing slice is shared with the caller: it's copied before being written to
+	shared      bool
+	lifecycle   lifecycle
+	plan        *plan
+	observation observation
+}
+
+// Hasher can be implemented by elements providing their own hash, which is then used by Distinct
+// instead of hashing the whole element. Its signature matches the hashstructure.Hashable interface,
+// so it is also honored for nested fields of non-comparable types.
+type Hasher interface {
+	Hash() (uint64, error)
 }
 
 /*
@@ -26,12 +39,19 @@ type Stream[T any] struct {
  */
 
 // From Creates a new Stream backed by the given [backingSlice]
-// the state of the given backingSlice will be updated by operation applied to the returned Stream
+// the state of the given backingSlice will be preserved: it's copied by the first operation writing to the Stream,
+// hence read-only operations don't allocate. See FromMutable for updating the backingSlice in place instead
 func From[T any](backingSlice []T) *Stream[T] {
-	return &Stream[T]{
-		slice:      backingSlice,
-		comparable: isComparableType[T](),
-	}
+	s := newStream(backingSlice, "slice")
+	s.shared = true
+	return s
+}
+
+// FromMutable Creates a new Stream backed by the given [backingSlice]
+// the state of the given backingSlice will be updated by operation applied to the returned Stream,
+// avoiding the copy From performs before writing to it
+func FromMutable[T any](backingSlice []T) *Stream[T] {
+	return newStream(backingSlice, "mutable slice")
 }
 
 // CopyFrom Creates a new Stream backed by a copy of the elements in the given [slice]
@@ -40,10 +60,7 @@ func CopyFrom[T any](slice []T) *Stream[T] {
 	sliceCopy := make([]T, len(slice))
 	copy(sliceCopy, slice)
 
-	return &Stream[T]{
-		slice:      sliceCopy,
-		comparable: isComparableType[T](),
-	}
+	return newStream(sliceCopy, "copy")
 }
 
 // Of Creates a new Stream backed by the given [elems]
@@ -53,7 +70,7 @@ func Of[T any](elems ...T) *Stream[T] {
 	for _, elem := range elems {
 		slice = append(slice, elem)
 	}
-	return From(slice)
+	return newStream(slice, "elements")
 }
 
 /*
@@ -63,34 +80,39 @@ func Of[T any](elems ...T) *Stream[T] {
 // Filter Returns a Stream containing only elements matching the given [predicate].
 // This operation is lazy and will be applied only upon calling a terminal operation on the Stream
 func (s *Stream[T]) Filter(p predicate[T]) *Stream[T] {
-	s.filters = append(s.filters, p)
+	defer s.exit(s.enter("Filter", false))
+	s.filters = append(s.filters, s.observedFilter(p))
 	return s
 }
 
 // Map Returns a new Stream containing the results of applying the given function to each element in the given Stream
 func Map[IN any, OUT any](s *Stream[IN], f mapper[IN, OUT]) *Stream[OUT] {
+	defer s.exit(s.enter("Map", true))
 	var newSlice []OUT
 
 	for _, elem := range s.filteredSlice() {
 		newSlice = append(newSlice, f(elem))
 	}
-	return From(newSlice)
+	return derive(s, newSlice)
 }
 
 // PMap Returns a new Stream containing the results of applying the given function to each element in the given Stream
 // in parallel. By default, PMap will launch a new goroutine per each element present in the provided Stream
 // If the [batching] flag is present, the parallel work is batched by number of available logical CPUs.
 func PMap[IN any, OUT any](s *Stream[IN], f mapper[IN, OUT], batching ...bool) *Stream[OUT] {
+	defer s.exit(s.enter("PMap", true))
+	mapIdx := func(idx int) OUT { return f(s.slice[idx]) }
 	if len(batching) == 0 {
-		return parallelLinearMap(s, f)
+		return parallelLinearMap(s, mapIdx)
 	} else {
-		return parallelBatchingMap(s, f)
+		return parallelBatchingMap(s, mapIdx)
 	}
 }
 
 // FlatMap Returns a single Stream of all elements yielded from results of [mapper] function
 // being invoked on each element of original Stream
 func FlatMap[IN any, OUT any](s *Stream[IN], f mapper[IN, *Stream[OUT]]) *Stream[OUT] {
+	defer s.exit(s.enter("FlatMap", true))
 	var newSlice []OUT
 
 	for _, elem := range s.filteredSlice() {
@@ -98,7 +120,7 @@ func FlatMap[IN any, OUT any](s *Stream[IN], f mapper[IN, *Stream[OUT]]) *Stream
 			newSlice = append(newSlice, slice)
 		}
 	}
-	return From(newSlice)
+	return derive(s, newSlice)
 }
 
 // Reduce Accumulates value starting with the given [start] value if provided, or with first element and applying
@@ -106,6 +128,7 @@ func FlatMap[IN any, OUT any](s *Stream[IN], f mapper[IN, *Stream[OUT]]) *Stream
 //
 //	operation: function that takes current accumulator value and an element, and calculates the next accumulator value.
 func Reduce[IN any, OUT any](s *Stream[IN], f reducer[OUT, IN], start ...OUT) (out OUT) {
+	defer s.exit(s.enter("Reduce", true))
 	if len(start) > 0 {
 		out = start[0]
 	}
@@ -119,6 +142,7 @@ func Reduce[IN any, OUT any](s *Stream[IN], f reducer[OUT, IN], start ...OUT) (o
 // and returns a map where each group key is associated with a slice of corresponding elements.
 // The returned map preserves the entry iteration order of the keys produced from the original Stream.
 func GroupBy[K comparable, V any](s *Stream[V], keySelector func(V) K) map[K][]V {
+	defer s.exit(s.enter("GroupBy", true))
 	grouping := make(map[K][]V, len(s.filteredSlice()))
 
 	for _, elem := range s.slice {
@@ -132,17 +156,29 @@ func GroupBy[K comparable, V any](s *Stream[V], keySelector func(V) K) map[K][]V
  * Internal Ops
  */
 
+// creates a new Stream owning the given backing slice, of the given source kind
+func newStream[T any](slice []T, sourceKind string) *Stream[T] {
+	return &Stream[T]{
+		slice:      slice,
+		comparable: isComparableType[T](),
+		hasher:     isHasherType[T](),
+		plan:       &plan{source: Source{Kind: sourceKind, Size: len(slice)}},
+	}
+}
+
 // returns true if the given generic type is Comparable
 func isComparableType[T any]() bool {
 	return reflect.TypeOf((*T)(nil)).Elem().Comparable()
 }
 
+// returns true if the given generic type implements the Hasher interface
+func isHasherType[T any]() bool {
+	return reflect.TypeOf((*T)(nil)).Elem().Implements(reflect.TypeOf((*Hasher)(nil)).Elem())
+}
+
 // calculates a hash for the given generic value
 func (s *Stream[T]) calculateHash(idx int) any {
-	if s.comparable {
-		return s.slice[idx]
-	}
-	hash, err := h.Hash(s.slice[idx], h.FormatV2, nil)
+	hash, err := s.hashKey(idx)
 	if err != nil {
 		// best effort: uses the value pointer
 		return &(s.slice[idx])
@@ -150,13 +186,64 @@ func (s *Stream[T]) calculateHash(idx int) any {
 	return hash
 }
 
+// calculates a hash for the given generic value, reporting hashing failures
+func (s *Stream[T]) hashKey(idx int) (any, error) {
+	return hashKeyOf(s.slice[idx], s.comparable, s.hasher)
+}
+
+// returns the key comparing the given [elem] like Distinct does: its Hash when it's a Hasher, the element itself
+// when it's [comparable], or its hashstructure hash otherwise
+func hashKeyOf[T any](elem T, comparable bool, hasher bool) (any, error) {
+	if hasher {
+		return any(elem).(Hasher).Hash()
+	}
+	if comparable {
+		return elem, nil
+	}
+	return h.Hash(elem, h.FormatV2, nil)
+}
+
 // returns the filtered backing slice after applying all registered filters
 func (s *Stream[T]) filteredSlice() []T {
-	applyFilters(&(s.slice), s.filters)
+	s.retain(s.filters)
 	s.filters = nil
+	s.notifyFilters()
+	if stage := s.currentStage(); stage != nil && stage.Out < 0 {
+		stage.Out = len(s.slice)
+	}
 	return s.slice
 }
 
+// copies the backing slice before writing to it, if it's still shared with the caller
+func (s *Stream[T]) own() {
+	if !s.shared {
+		return
+	}
+	owned := make([]T, len(s.slice))
+	copy(owned, s.slice)
+	s.slice, s.shared = owned, false
+}
+
+// retains the elements matching all given filters, in place if the backing slice isn't shared with the caller
+func (s *Stream[T]) retain(filters []predicate[T]) {
+	if !s.shared || len(filters) == 0 {
+		applyFilters(&(s.slice), filters)
+		return
+	}
+	// an empty non-nil slice: appending to it allocates a new backing array
+	retained := s.slice[:0:0]
+filtering:
+	for _, elem := range s.slice {
+		for _, filter := range filters {
+			if !filter(elem) {
+				continue filtering
+			}
+		}
+		retained = append(retained, elem)
+	}
+	s.slice, s.shared = retained, false
+}
+
 // Applies all lazy filters to the Stream
 func applyFilters[T any](slice *[]T, filters []predicate[T]) {
 	if len(filters) == 0 {
@@ -180,9 +267,9 @@ filtering:
 	*slice = (*slice)[:i]
 }
 
-// parallelLinearMap Returns a new Stream containing the results of applying the given function to each element
-// in the given Stream in parallel. A new goroutine is launched per each element present in the provided Stream.
-func parallelLinearMap[IN any, OUT any](s *Stream[IN], f mapper[IN, OUT]) *Stream[OUT] {
+// parallelLinearMap Returns a new Stream containing the results of applying the given function to the index of each
+// element in the given Stream in parallel. A new goroutine is launched per each element present in the provided Stream.
+func parallelLinearMap[IN any, OUT any](s *Stream[IN], f mapper[int, OUT]) *Stream[OUT] {
 	resultSlice := make([]OUT, len(s.filteredSlice()))
 	var wg sync.WaitGroup
 	wg.Add(len(s.slice))
@@ -190,25 +277,28 @@ func parallelLinearMap[IN any, OUT any](s *Stream[IN], f mapper[IN, OUT]) *Strea
 	// launching the goroutines
 	for i := range s.slice {
 		// launch goroutine that executes the mapping asynchronously
-		go func(f mapper[IN, OUT], idx int) {
+		go func(f mapper[int, OUT], idx int) {
 			defer wg.Done() // signals completion
-			resultSlice[idx] = f(s.slice[idx])
+			resultSlice[idx] = f(idx)
 		}(f, i)
 	}
 	// blocking: waits for all goroutines to complete
 	wg.Wait()
-	return From(resultSlice)
+	if stage := s.currentStage(); stage != nil {
+		stage.Goroutines = len(s.slice)
+	}
+	return derive(s, resultSlice)
 }
 
-// parallelBatchingMap Returns a new Stream containing the results of applying the given function to each element
-// in the given Stream in parallel. The parallel work is batched by number of available logical CPUs.
-func parallelBatchingMap[IN any, OUT any](s *Stream[IN], f mapper[IN, OUT]) *Stream[OUT] {
+// parallelBatchingMap Returns a new Stream containing the results of applying the given function to the index of each
+// element in the given Stream in parallel. The parallel work is batched by number of available logical CPUs.
+func parallelBatchingMap[IN any, OUT any](s *Stream[IN], f mapper[int, OUT]) *Stream[OUT] {
 	streamSize := len(s.filteredSlice())
 	resultSlice := make([]OUT, streamSize)
 	batchSize := slices.Min(Of(runtime.NumCPU(), streamSize).slice)
 
 	if streamSize == 0 {
-		return From(resultSlice)
+		return derive(s, resultSlice)
 	}
 
 	batches := int(math.Ceil(float64(streamSize / batchSize)))
@@ -227,11 +317,14 @@ func parallelBatchingMap[IN any, OUT any](s *Stream[IN], f mapper[IN, OUT]) *Str
 		go func() {
 			defer wg.Done()
 			for idx := lowerBound; idx < upperBound; idx++ {
-				resultSlice[idx] = f(s.slice[idx])
+				resultSlice[idx] = f(idx)
 			}
 		}()
 	}
 	// blocking: waits for all goroutines to complete
 	wg.Wait()
-	return From(resultSlice)
+	if stage := s.currentStage(); stage != nil {
+		stage.Goroutines, stage.Batched = batches+1, true
+	}
+	return derive(s, resultSlice)
 }
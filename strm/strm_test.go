@@ -22,6 +22,58 @@ func TestFrom(t *testing.T) {
 	assert.Equal(t, 3, len(slice), "wrong length")
 }
 
+func TestFromPreservesBackingSlice(t *testing.T) {
+	// prepare
+	initSlice := []int{3, 1, 2, 2, 5}
+	isOdd := func(n int) bool { return n%2 != 0 }
+
+	// call
+	filtered := From(initSlice).Filter(isOdd).ToSlice()
+	distinct := From(initSlice).Distinct().ToSlice()
+	reversed := From(initSlice).Reversed().ToSlice()
+	applied := From(initSlice).ApplyOnEach(func(n int) int { return n * 10 }).ToSlice()
+	taken := From(initSlice).Take(2).Append([]int{7}).ToSlice()
+	dropped := From(initSlice).Drop(3).ToSlice()
+	sorted := RangeFrom(initSlice).Sorted().ToSlice()
+
+	// assert
+	assert.Equal(t, []int{3, 1, 2, 2, 5}, initSlice, "backing slice was modified")
+	assert.Equal(t, []int{3, 1, 5}, filtered, "wrong value")
+	assert.Equal(t, []int{3, 1, 2, 5}, distinct, "wrong value")
+	assert.Equal(t, []int{5, 2, 2, 1, 3}, reversed, "wrong value")
+	assert.Equal(t, []int{30, 10, 20, 20, 50}, applied, "wrong value")
+	assert.Equal(t, []int{3, 1, 7}, taken, "wrong value")
+	assert.Equal(t, []int{2, 5}, dropped, "wrong value")
+	assert.Equal(t, []int{1, 2, 2, 3, 5}, sorted, "wrong value")
+}
+
+func TestFromReadOnlyPipelineDoesNotCopy(t *testing.T) {
+	// prepare
+	initSlice := []int{1, 2, 3, 4}
+
+	// call
+	got := From(initSlice).Drop(1).Take(2).ToSlice()
+
+	// assert
+	assert.Equal(t, []int{2, 3}, got, "wrong value")
+	assert.Same(t, &initSlice[1], &got[0], "read-only ops shouldn't copy the backing slice")
+}
+
+func TestFromMutable(t *testing.T) {
+	// prepare
+	initSlice := []int{1, 2, 3, 4}
+
+	// call
+	got := FromMutable(initSlice).
+		Filter(func(n int) bool { return n > 2 }).
+		ApplyOnEach(func(n int) int { return n * 10 }).
+		ToSlice()
+
+	// assert
+	assert.Equal(t, []int{30, 40}, got, "wrong value")
+	assert.Equal(t, []int{30, 40, 0, 0}, initSlice, "backing slice should be updated in place")
+}
+
 func TestFilterCopyFrom(t *testing.T) {
 	// prepare
 	initSlice := [][]int{{1}, {1, 2}, {1, 2, 3}}
@@ -186,11 +238,11 @@ func TestGroupBy(t *testing.T) {
 		name string
 		age  int
 	}
-	stream := Of(Person{"Tim", 30}, Person{"Bil", 40}, Person{"John", 30}, Person{"Tim", 35})
+	people := []Person{{"Tim", 30}, {"Bil", 40}, {"John", 30}, {"Tim", 35}}
 
 	// call
-	byAge := GroupBy(stream, func(it Person) int { return it.age })
-	byName := GroupBy(stream, func(it Person) string { return it.name })
+	byAge := GroupBy(From(people), func(it Person) int { return it.age })
+	byName := GroupBy(From(people), func(it Person) string { return it.name })
 
 	// assert
 	assert.Equal(t, 3, len(byAge), "wrong length")
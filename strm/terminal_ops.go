@@ -8,11 +8,13 @@ import (
 
 // ToSlice returns a Slice containing all the elements of this Stream
 func (s *Stream[T]) ToSlice() []T {
+	defer s.exit(s.enter("ToSlice", true))
 	return s.filteredSlice()
 }
 
 // ForEach Performs the given action on each element of the Stream
 func (s *Stream[T]) ForEach(action func(T)) {
+	defer s.exit(s.enter("ForEach", true))
 	for _, elem := range s.filteredSlice() {
 		action(elem)
 	}
@@ -20,6 +22,7 @@ func (s *Stream[T]) ForEach(action func(T)) {
 
 // Any Returns true if at least one element matches the given predicate.
 func (s *Stream[T]) Any(p predicate[T]) bool {
+	defer s.exit(s.enter("Any", true))
 	for _, elem := range s.filteredSlice() {
 		if p(elem) {
 			return true
@@ -30,6 +33,7 @@ func (s *Stream[T]) Any(p predicate[T]) bool {
 
 // All Returns true if all elements match the given predicate.
 func (s *Stream[T]) All(p predicate[T]) bool {
+	defer s.exit(s.enter("All", true))
 	for _, elem := range s.filteredSlice() {
 		if !p(elem) {
 			return false
@@ -40,16 +44,19 @@ func (s *Stream[T]) All(p predicate[T]) bool {
 
 // None Returns true if no elements match the given predicate.
 func (s *Stream[T]) None(p predicate[T]) bool {
+	defer s.exit(s.enter("None", true))
 	return !s.Any(p)
 }
 
 // Count Returns the number of elements in this Stream
 func (s *Stream[T]) Count() int {
+	defer s.exit(s.enter("Count", true))
 	return len(s.filteredSlice())
 }
 
 // CountBy Returns the number of elements matching the given predicate [p].
 func (s *Stream[T]) CountBy(p predicate[T]) (count int) {
+	defer s.exit(s.enter("CountBy", true))
 	for _, elem := range s.filteredSlice() {
 		if p(elem) {
 			count++
@@ -60,6 +67,7 @@ func (s *Stream[T]) CountBy(p predicate[T]) (count int) {
 
 // SumBy Returns the sum of all values produced by [selector] function applied to each element in the Stream.
 func (s *Stream[T]) SumBy(selector func(t T) int) (sum int) {
+	defer s.exit(s.enter("SumBy", true))
 	for _, elem := range s.filteredSlice() {
 		sum += selector(elem)
 	}
@@ -68,6 +76,7 @@ func (s *Stream[T]) SumBy(selector func(t T) int) (sum int) {
 
 // FirstBy Returns the first element of this Stream matching the given predicate [p].
 func (s *Stream[T]) FirstBy(p predicate[T]) (t T) {
+	defer s.exit(s.enter("FirstBy", true))
 	for _, elem := range s.filteredSlice() {
 		if p(elem) {
 			return elem
@@ -78,6 +87,7 @@ func (s *Stream[T]) FirstBy(p predicate[T]) (t T) {
 
 // First Returns the first element of this Stream
 func (s *Stream[T]) First() (t T) {
+	defer s.exit(s.enter("First", true))
 	if len(s.filteredSlice()) == 0 {
 		return
 	}
@@ -86,6 +96,7 @@ func (s *Stream[T]) First() (t T) {
 
 // Last Returns the last element of this Stream
 func (s *Stream[T]) Last() (t T) {
+	defer s.exit(s.enter("Last", true))
 	if len(s.filteredSlice()) == 0 {
 		return
 	}
@@ -96,6 +107,7 @@ func (s *Stream[T]) Last() (t T) {
 // Performs O(n) search for Streams of Comparable types like structs, pointers and primitive types;
 // Uses reflect.DeepEqual for non-comparable types
 func (s *Stream[T]) Contains(element T) bool {
+	defer s.exit(s.enter("Contains", true))
 	if s.comparable {
 		for _, a := range s.filteredSlice() {
 			if any(a) == any(element) {
@@ -115,6 +127,7 @@ func (s *Stream[T]) Contains(element T) bool {
 // JoinToString Creates a string from all the elements separated using [separator].
 // makes use of fmt.Sprint() to dynamically convert the incoming generic type T to a string representation
 func (s *Stream[T]) JoinToString(delimiter string) string {
+	defer s.exit(s.enter("JoinToString", true))
 	var sb strings.Builder
 	for i := 0; i < len(s.filteredSlice()); i++ {
 		sb.WriteString(fmt.Sprint(s.slice[i]))
@@ -0,0 +1,199 @@
+package strm
+
+import (
+	"errors"
+	"iter"
+	"time"
+)
+
+// ErrTimeout Returned by LazyStream.Err when the source of a LazyStream didn't produce an element in time, see Timeout
+var ErrTimeout = errors.New("strm: timeout")
+
+// WithClock Sets the Clock of the time-aware operations of this LazyStream, SystemClock by default
+func (s *LazyStream[T]) WithClock(clock Clock) *LazyStream[T] {
+	s.src.clock = clock
+	return s
+}
+
+// Throttle Lazily keeps the first element of this LazyStream and drops the following ones pulled within the given
+// [interval] of the last kept element, limiting the rate of elements to one per [interval]
+func (s *LazyStream[T]) Throttle(interval time.Duration) *LazyStream[T] {
+	s.use("Throttle", false)
+	seq := s.seq
+	s.seq = func(yield func(T) bool) {
+		clock := orSystemClock(s.src.clock)
+		var last time.Time
+		kept := false
+		for elem := range seq {
+			if now := clock.Now(); !kept || now.Sub(last) >= interval {
+				last, kept = now, true
+				if !yield(elem) {
+					return
+				}
+			}
+		}
+	}
+	return s
+}
+
+// Debounce Lazily keeps the elements of this LazyStream which aren't followed by another one within the given
+// [d], e.g. the last of a burst of changes. The last element is kept once the source is exhausted.
+// The source is pulled by another goroutine, which stops once the source is exhausted or yields its next element,
+// or once its read fails as the source is released by the end of the terminal operation, e.g. a closed file: the
+// errors reported by the source afterwards are dropped. A source which isn't interrupted by its release, like a
+// channel, keeps the goroutine until it produces its next element or ends.
+func (s *LazyStream[T]) Debounce(d time.Duration) *LazyStream[T] {
+	s.use("Debounce", false)
+	seq := s.seq
+	s.seq = func(yield func(T) bool) {
+		clock := orSystemClock(s.src.clock)
+		in, stop := pull(seq)
+		defer stop()
+		var pending T
+		var quiet <-chan time.Time
+		for {
+			select {
+			case elem, ok := <-in:
+				if !ok {
+					if quiet != nil {
+						yield(pending)
+					}
+					return
+				}
+				pending, quiet = elem, clock.After(d)
+			case <-quiet:
+				quiet = nil
+				if !yield(pending) {
+					return
+				}
+			}
+		}
+	}
+	return s
+}
+
+// Sample Lazily keeps the latest element of this LazyStream at the end of every [interval], if any was pulled
+// during it. The source is pulled by another goroutine, see Debounce.
+func (s *LazyStream[T]) Sample(interval time.Duration) *LazyStream[T] {
+	s.use("Sample", false)
+	seq := s.seq
+	s.seq = func(yield func(T) bool) {
+		clock := orSystemClock(s.src.clock)
+		in, stop := pull(seq)
+		defer stop()
+		var latest T
+		fresh := false
+		tick := clock.After(interval)
+		for {
+			select {
+			case elem, ok := <-in:
+				if !ok {
+					return
+				}
+				latest, fresh = elem, true
+			case <-tick:
+				tick = clock.After(interval)
+				if fresh {
+					fresh = false
+					if !yield(latest) {
+						return
+					}
+				}
+			}
+		}
+	}
+	return s
+}
+
+// Timeout Lazily stops this LazyStream with ErrTimeout, returned by Err, when its source doesn't produce an element
+// within the given [d], from the start of the pulling or from the previous element.
+// The source is pulled by another goroutine, see Debounce: the read of the source pending when the time is out is
+// interrupted by the release of the source, once the terminal operation ends.
+func (s *LazyStream[T]) Timeout(d time.Duration) *LazyStream[T] {
+	s.use("Timeout", false)
+	seq := s.seq
+	s.seq = func(yield func(T) bool) {
+		clock := orSystemClock(s.src.clock)
+		in, stop := pull(seq)
+		defer stop()
+		deadline := clock.After(d)
+		for {
+			select {
+			case elem, ok := <-in:
+				if !ok || !yield(elem) {
+					return
+				}
+				deadline = clock.After(d)
+			case <-deadline:
+				s.src.fail(ErrTimeout)
+				return
+			}
+		}
+	}
+	return s
+}
+
+// BufferTime Returns a new LazyStream of the elements of the given LazyStream buffered into slices: a buffer is
+// emitted once it holds [maxSize] elements, or once the given [d] elapsed since its first element, and the last one
+// once the source is exhausted. A non-positive [maxSize] doesn't limit the buffers.
+// The source is pulled by another goroutine, see Debounce. The given LazyStream is consumed, see LazyMap.
+func BufferTime[T any](s *LazyStream[T], d time.Duration, maxSize int) *LazyStream[[]T] {
+	s.handOver("BufferTime")
+	seq := s.seq
+	return &LazyStream[[]T]{src: s.src, seq: func(yield func([]T) bool) {
+		clock := orSystemClock(s.src.clock)
+		in, stop := pull(seq)
+		defer stop()
+		var buffer []T
+		var flush <-chan time.Time
+		for {
+			select {
+			case elem, ok := <-in:
+				if !ok {
+					if len(buffer) > 0 {
+						yield(buffer)
+					}
+					return
+				}
+				if len(buffer) == 0 {
+					flush = clock.After(d)
+				}
+				if buffer = append(buffer, elem); len(buffer) == maxSize {
+					full := buffer
+					buffer, flush = nil, nil
+					if !yield(full) {
+						return
+					}
+				}
+			case <-flush:
+				full := buffer
+				buffer, flush = nil, nil
+				if !yield(full) {
+					return
+				}
+			}
+		}
+	}}
+}
+
+/*
+ * Internal Ops
+ */
+
+// pulls the elements of the given iterator from a new goroutine into the returned channel, closed once the iterator
+// is exhausted. The returned stop function makes the goroutine return once the iterator yields its next element, a
+// pending read being interrupted by the release of the source, see Debounce.
+func pull[T any](seq iter.Seq[T]) (<-chan T, func()) {
+	ch, done := make(chan T), make(chan struct{})
+	go func() {
+		defer close(ch)
+		for elem := range seq {
+			select {
+			case ch <- elem:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return ch, func() { close(done) }
+}
@@ -0,0 +1,243 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	// prepare
+	clock := NewManualClock(windowOrigin)
+	seq := func(yield func(int) bool) {
+		for i, offset := range []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 5 * time.Second} {
+			clock.Advance(offset - clock.Now().Sub(windowOrigin))
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	// call
+	kept, err := FromSeq(seq).WithClock(clock).Throttle(2 * time.Second).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 4}, kept, "wrong throttled elements")
+}
+
+func TestDebounce(t *testing.T) {
+	// prepare
+	clock := NewManualClock(windowOrigin)
+	source := newHandoff[int]()
+	out := collectAsync(FromSeq(source.seq).WithClock(clock).Debounce(time.Second))
+
+	// call & assert
+	source.send(1)
+	clock.BlockUntil(1)
+	source.send(2)
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	assert.Equal(t, 2, <-out, "the last element of a burst should be kept")
+
+	source.send(3)
+	clock.BlockUntil(1)
+	clock.Advance(500 * time.Millisecond)
+	source.send(4)
+	clock.BlockUntil(2)
+	clock.Advance(500 * time.Millisecond)
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, 4, <-out, "the last element of a burst should be kept")
+
+	source.send(5)
+	source.close()
+	assert.Equal(t, 5, <-out, "the pending element should be kept once the source is exhausted")
+	assert.Empty(t, drain(out), "no more elements expected")
+}
+
+func TestSampleInterval(t *testing.T) {
+	// prepare
+	clock := NewManualClock(windowOrigin)
+	source := newHandoff[int]()
+	out := collectAsync(FromSeq(source.seq).WithClock(clock).Sample(time.Second))
+
+	// call & assert
+	clock.BlockUntil(1)
+	source.send(1)
+	source.send(2)
+	clock.Advance(time.Second)
+	assert.Equal(t, 2, <-out, "the latest element should be sampled")
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	clock.BlockUntil(1)
+	source.send(3)
+	clock.Advance(time.Second)
+	assert.Equal(t, 3, <-out, "intervals without elements should be skipped")
+
+	source.send(4)
+	source.close()
+	assert.Empty(t, drain(out), "pending elements aren't sampled once the source is exhausted")
+}
+
+func TestTimeout(t *testing.T) {
+	// prepare
+	clock := NewManualClock(windowOrigin)
+	source := newHandoff[int]()
+	s := FromSeq(source.seq).WithClock(clock).Timeout(time.Second)
+	out := collectAsync(s)
+
+	// call & assert
+	clock.BlockUntil(1)
+	clock.Advance(900 * time.Millisecond)
+	source.send(1)
+	assert.Equal(t, 1, <-out, "elements in time should be kept")
+
+	clock.BlockUntil(2)
+	clock.Advance(900 * time.Millisecond)
+	clock.Advance(100 * time.Millisecond)
+	assert.Empty(t, drain(out), "no more elements expected")
+	assert.ErrorIs(t, s.Err(), ErrTimeout, "wrong error")
+}
+
+func TestTimeoutInterruptsPendingRead(t *testing.T) {
+	// prepare
+	clock := NewManualClock(windowOrigin)
+	source, interrupted := blockedLines()
+	out := collectAsync(source.WithClock(clock).Timeout(time.Second))
+
+	// call
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+
+	// assert
+	assert.Empty(t, drain(out), "no elements expected")
+	select {
+	case <-interrupted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the pending read should be interrupted by the release of the source")
+	}
+	assert.ErrorIs(t, source.Err(), ErrTimeout, "the errors of the interrupted read should be dropped")
+}
+
+func TestBufferTime(t *testing.T) {
+	// prepare
+	clock := NewManualClock(windowOrigin)
+	source := newHandoff[int]()
+	out := collectAsync(BufferTime(FromSeq(source.seq).WithClock(clock), time.Second, 2))
+
+	// call & assert
+	source.send(1)
+	source.send(2)
+	assert.Equal(t, []int{1, 2}, <-out, "full buffers should be emitted")
+
+	source.send(3)
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	assert.Equal(t, []int{3}, <-out, "buffers should be emitted once their time elapsed")
+
+	source.send(4)
+	source.close()
+	assert.Equal(t, [][]int{{4}}, drain(out), "the last buffer should be emitted once the source is exhausted")
+}
+
+func TestTimingOpsStopEarly(t *testing.T) {
+	// prepare
+	ch := make(chan int, 10)
+	for i := range 10 {
+		ch <- i
+	}
+	close(ch)
+
+	// call
+	first, err := BufferTime(FromChan(ch), time.Hour, 3).Take(2).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, [][]int{{0, 1, 2}, {3, 4, 5}}, first, "wrong buffers")
+}
+
+func TestManualClock(t *testing.T) {
+	// prepare
+	clock := NewManualClock(windowOrigin)
+	later, sooner, now := clock.After(2*time.Second), clock.After(time.Second), clock.After(0)
+
+	// call
+	clock.Advance(time.Second)
+
+	// assert
+	assert.Equal(t, windowOrigin, <-now, "non-positive durations should fire immediately")
+	assert.Equal(t, windowOrigin.Add(time.Second), <-sooner, "wrong fired time")
+	assert.Empty(t, later, "timer shouldn't fire before its deadline")
+	assert.Equal(t, windowOrigin.Add(time.Second), clock.Now(), "wrong current time")
+	assert.WithinDuration(t, time.Now(), SystemClock.Now(), time.Minute, "wrong system time")
+}
+
+// a source handing over its elements one at a time: sending an element returns once it's been received
+type handoff[T any] struct {
+	ch        chan T
+	delivered chan struct{}
+}
+
+func newHandoff[T any]() *handoff[T] {
+	return &handoff[T]{make(chan T), make(chan struct{})}
+}
+
+func (h *handoff[T]) seq(yield func(T) bool) {
+	for elem := range h.ch {
+		if !yield(elem) {
+			return
+		}
+		h.delivered <- struct{}{}
+	}
+}
+
+func (h *handoff[T]) send(elem T) {
+	h.ch <- elem
+	<-h.delivered
+}
+
+func (h *handoff[T]) close() {
+	close(h.ch)
+}
+
+// returns a LazyStream of lines whose read blocks until its source is released, closing [interrupted] once it returns
+func blockedLines() (s *LazyStream[string], interrupted <-chan struct{}) {
+	r, _ := io.Pipe()
+	done := make(chan struct{})
+	s = Lines(readerFunc(func(p []byte) (int, error) {
+		defer close(done)
+		return r.Read(p)
+	}))
+	s.src.closer = r.Close
+	return s, done
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+// pulls the elements of the given LazyStream from another goroutine
+func collectAsync[T any](s *LazyStream[T]) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		s.ForEach(func(elem T) { out <- elem })
+	}()
+	return out
+}
+
+func drain[T any](ch <-chan T) []T {
+	return slices.Collect(func(yield func(T) bool) {
+		for elem := range ch {
+			if !yield(elem) {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package strm
+
+import (
+	"sort"
+	"time"
+)
+
+// TimeWindow A window of event time, with the elements whose timestamps fall into it
+type TimeWindow[T any] struct {
+	// Start the inclusive start of the window
+	Start time.Time
+	// End the exclusive end of the window
+	End      time.Time
+	Elements []T
+}
+
+// WindowAggregate The aggregated value of the elements of a TimeWindow, see WindowReducer
+type WindowAggregate[R any] struct {
+	Start time.Time
+	End   time.Time
+	Value R
+}
+
+/*
+ * Top-Level Ops
+ */
+
+// TumblingWindow Returns a new Stream of the consecutive, non-overlapping TimeWindows of the given [size] holding
+// the elements of the given Stream, by the timestamps returned by the given [timestampFn].
+// Windows are aligned on multiples of [size] since the zero time, e.g. on minutes, and empty windows are omitted.
+// The given [size] must be positive.
+// Elements don't need to be sorted, the order of elements with equal timestamps being preserved.
+func TumblingWindow[T any](s *Stream[T], timestampFn func(T) time.Time, size time.Duration) *Stream[TimeWindow[T]] {
+	defer s.exit(s.enter("TumblingWindow", true))
+	return derive(s, collectWindows(s.sortedByTime(timestampFn), timestampFn, newSlidingWindower[T](size, size)))
+}
+
+// SlidingWindow Returns a new Stream of the TimeWindows of the given [size], starting every [slide], holding
+// the elements of the given Stream by the timestamps returned by the given [timestampFn]: elements belong to
+// every window covering their timestamp, windows overlapping when [slide] is lower than [size].
+// Windows are aligned on multiples of [slide] since the zero time, and empty windows are omitted.
+// Both [size] and [slide] must be positive.
+func SlidingWindow[T any](s *Stream[T], timestampFn func(T) time.Time, size time.Duration, slide time.Duration) *Stream[TimeWindow[T]] {
+	defer s.exit(s.enter("SlidingWindow", true))
+	return derive(s, collectWindows(s.sortedByTime(timestampFn), timestampFn, newSlidingWindower[T](size, slide)))
+}
+
+// SessionWindow Returns a new Stream of the sessions of activity of the given Stream, by the timestamps returned by
+// the given [timestampFn]: a session ends once no element follows its last one within the given [gap].
+// Each TimeWindow starts at the timestamp of its first element and ends [gap] after its last one.
+// The given [gap] must be positive.
+func SessionWindow[T any](s *Stream[T], timestampFn func(T) time.Time, gap time.Duration) *Stream[TimeWindow[T]] {
+	defer s.exit(s.enter("SessionWindow", true))
+	return derive(s, collectWindows(s.sortedByTime(timestampFn), timestampFn, newSessionWindower[T](gap)))
+}
+
+// LazyTumblingWindow Returns a new LazyStream of the TimeWindows of the elements of the given LazyStream,
+// see TumblingWindow, emitting each window once an element past its end is pulled, and the last ones once the source
+// is exhausted. Elements are expected in event-time order: late ones, pulled after an element past the end of one of
+// their windows, are dropped.
+// The given LazyStream is consumed, see LazyMap.
+func LazyTumblingWindow[T any](s *LazyStream[T], timestampFn func(T) time.Time, size time.Duration) *LazyStream[TimeWindow[T]] {
+	s.handOver("LazyTumblingWindow")
+	return &LazyStream[TimeWindow[T]]{src: s.src, seq: lazyWindows(s, timestampFn, newSlidingWindower[T](size, size))}
+}
+
+// LazySlidingWindow Returns a new LazyStream of the sliding TimeWindows of the elements of the given LazyStream,
+// see SlidingWindow and LazyTumblingWindow
+func LazySlidingWindow[T any](s *LazyStream[T], timestampFn func(T) time.Time, size time.Duration, slide time.Duration) *LazyStream[TimeWindow[T]] {
+	s.handOver("LazySlidingWindow")
+	return &LazyStream[TimeWindow[T]]{src: s.src, seq: lazyWindows(s, timestampFn, newSlidingWindower[T](size, slide))}
+}
+
+// LazySessionWindow Returns a new LazyStream of the sessions of activity of the given LazyStream,
+// see SessionWindow and LazyTumblingWindow. Late elements, before the start of the open session or the end of an
+// emitted one, are dropped.
+func LazySessionWindow[T any](s *LazyStream[T], timestampFn func(T) time.Time, gap time.Duration) *LazyStream[TimeWindow[T]] {
+	s.handOver("LazySessionWindow")
+	return &LazyStream[TimeWindow[T]]{src: s.src, seq: lazyWindows(s, timestampFn, newSessionWindower[T](gap))}
+}
+
+// WindowReducer Returns a mapper reducing the elements of a TimeWindow with the given reducer [f] and [start] value,
+// e.g. for Map or LazyMap over a Stream of windows
+func WindowReducer[T any, R any](f func(R, T) R, start R) func(TimeWindow[T]) WindowAggregate[R] {
+	return func(w TimeWindow[T]) WindowAggregate[R] {
+		value := start
+		for _, elem := range w.Elements {
+			value = f(value, elem)
+		}
+		return WindowAggregate[R]{w.Start, w.End, value}
+	}
+}
+
+/*
+ * Internal Ops
+ */
+
+// assigns elements, in event-time order, to windows
+type windower[T any] interface {
+	// adds the given element, returning the windows it closed
+	add(elem T, ts time.Time) []TimeWindow[T]
+	// returns the windows still open
+	flush() []TimeWindow[T]
+}
+
+// returns a copy of the filtered elements of this Stream, stably sorted by the given timestamps
+func (s *Stream[T]) sortedByTime(timestampFn func(T) time.Time) []T {
+	sorted := append([]T(nil), s.filteredSlice()...)
+	sort.SliceStable(sorted, func(i, j int) bool { return timestampFn(sorted[i]).Before(timestampFn(sorted[j])) })
+	return sorted
+}
+
+// assigns all the given elements to windows
+func collectWindows[T any](elems []T, timestampFn func(T) time.Time, w windower[T]) []TimeWindow[T] {
+	windows := make([]TimeWindow[T], 0)
+	for _, elem := range elems {
+		windows = append(windows, w.add(elem, timestampFn(elem))...)
+	}
+	return append(windows, w.flush()...)
+}
+
+// returns an iterator of the windows of the elements pulled from the given LazyStream
+func lazyWindows[T any](s *LazyStream[T], timestampFn func(T) time.Time, w windower[T]) func(yield func(TimeWindow[T]) bool) {
+	seq := s.seq
+	return func(yield func(TimeWindow[T]) bool) {
+		for elem := range seq {
+			for _, window := range w.add(elem, timestampFn(elem)) {
+				if !yield(window) {
+					return
+				}
+			}
+		}
+		for _, window := range w.flush() {
+			if !yield(window) {
+				return
+			}
+		}
+	}
+}
+
+// assigns elements to windows of a fixed size starting every slide, tumbling when both are equal
+type slidingWindower[T any] struct {
+	size  time.Duration
+	slide time.Duration
+	// the open windows, by increasing start
+	open []TimeWindow[T]
+	// the latest timestamp added, the windows ending before it being closed
+	watermark time.Time
+	started   bool
+}
+
+func newSlidingWindower[T any](size time.Duration, slide time.Duration) *slidingWindower[T] {
+	if size <= 0 || slide <= 0 {
+		panic("strm: window size and slide must be positive")
+	}
+	return &slidingWindower[T]{size: size, slide: slide}
+}
+
+func (w *slidingWindower[T]) add(elem T, ts time.Time) []TimeWindow[T] {
+	// the first window covering the given timestamp
+	start := ts.Add(-w.size).Truncate(w.slide)
+	if !start.After(ts.Add(-w.size)) {
+		start = start.Add(w.slide)
+	}
+	// drops the late elements, one of their windows being closed already
+	if w.started && !start.Add(w.size).After(w.watermark) {
+		return nil
+	}
+	if !w.started || ts.After(w.watermark) {
+		w.watermark, w.started = ts, true
+	}
+
+	// closes the windows ending before the given timestamp
+	closed := 0
+	for closed < len(w.open) && !ts.Before(w.open[closed].End) {
+		closed++
+	}
+	windows := w.open[:closed:closed]
+	w.open = w.open[closed:]
+
+	// opens the windows covering the given timestamp
+	for ; !start.After(ts); start = start.Add(w.slide) {
+		if len(w.open) == 0 || start.After(w.open[len(w.open)-1].Start) {
+			w.open = append(w.open, TimeWindow[T]{Start: start, End: start.Add(w.size)})
+		}
+	}
+
+	for i := range w.open {
+		if !ts.Before(w.open[i].Start) {
+			w.open[i].Elements = append(w.open[i].Elements, elem)
+		}
+	}
+	return windows
+}
+
+func (w *slidingWindower[T]) flush() []TimeWindow[T] {
+	windows := w.open
+	w.open = nil
+	return windows
+}
+
+// assigns elements to sessions of activity separated by gaps
+type sessionWindower[T any] struct {
+	gap     time.Duration
+	session *TimeWindow[T]
+	// the start of the open session, or else the end of the last emitted one, the elements before it being late
+	watermark time.Time
+	started   bool
+}
+
+func newSessionWindower[T any](gap time.Duration) *sessionWindower[T] {
+	if gap <= 0 {
+		panic("strm: session gap must be positive")
+	}
+	return &sessionWindower[T]{gap: gap}
+}
+
+func (w *sessionWindower[T]) add(elem T, ts time.Time) []TimeWindow[T] {
+	// drops the late elements, which would move the bounds of the open session or of an emitted one
+	if w.started && ts.Before(w.watermark) {
+		return nil
+	}
+	var windows []TimeWindow[T]
+	if w.session != nil && !ts.Before(w.session.End) {
+		windows = w.flush()
+	}
+	if w.session == nil {
+		w.session = &TimeWindow[T]{Start: ts}
+		w.watermark, w.started = ts, true
+	}
+	w.session.Elements = append(w.session.Elements, elem)
+	if end := ts.Add(w.gap); end.After(w.session.End) {
+		w.session.End = end
+	}
+	return windows
+}
+
+func (w *sessionWindower[T]) flush() []TimeWindow[T] {
+	if w.session == nil {
+		return nil
+	}
+	windows := []TimeWindow[T]{*w.session}
+	w.watermark, w.session = w.session.End, nil
+	return windows
+}
@@ -0,0 +1,177 @@
+package strm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"testing"
+	"time"
+)
+
+type timedEvent struct {
+	at    time.Time
+	value int
+}
+
+var windowOrigin = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+func eventAt(offset time.Duration, value int) timedEvent {
+	return timedEvent{windowOrigin.Add(offset), value}
+}
+
+func eventTime(e timedEvent) time.Time {
+	return e.at
+}
+
+func TestTumblingWindow(t *testing.T) {
+	// prepare
+	events := []timedEvent{
+		eventAt(10*time.Second, 1), eventAt(70*time.Second, 3), eventAt(0, 2),
+		eventAt(59*time.Second, 4), eventAt(185*time.Second, 5),
+	}
+
+	// call
+	windows := TumblingWindow(From(events), eventTime, time.Minute).ToSlice()
+
+	// assert
+	assert.Equal(t, []TimeWindow[timedEvent]{
+		{windowOrigin, windowOrigin.Add(time.Minute), []timedEvent{eventAt(0, 2), eventAt(10*time.Second, 1), eventAt(59*time.Second, 4)}},
+		{windowOrigin.Add(time.Minute), windowOrigin.Add(2 * time.Minute), []timedEvent{eventAt(70*time.Second, 3)}},
+		{windowOrigin.Add(3 * time.Minute), windowOrigin.Add(4 * time.Minute), []timedEvent{eventAt(185*time.Second, 5)}},
+	}, windows, "wrong windows")
+	assert.Equal(t, []timedEvent{eventAt(10*time.Second, 1), eventAt(70*time.Second, 3), eventAt(0, 2),
+		eventAt(59*time.Second, 4), eventAt(185*time.Second, 5)}, events, "source slice should be preserved")
+}
+
+func TestTumblingWindowReducer(t *testing.T) {
+	// prepare
+	s := Of(eventAt(0, 2), eventAt(30*time.Second, 3), eventAt(90*time.Second, 4))
+
+	// call
+	counts := Map(TumblingWindow(s, eventTime, time.Minute), WindowReducer(func(n int, _ timedEvent) int { return n + 1 }, 0))
+
+	// assert
+	assert.Equal(t, []WindowAggregate[int]{
+		{windowOrigin, windowOrigin.Add(time.Minute), 2},
+		{windowOrigin.Add(time.Minute), windowOrigin.Add(2 * time.Minute), 1},
+	}, counts.ToSlice(), "wrong per-minute counts")
+}
+
+func TestSlidingWindow(t *testing.T) {
+	// prepare
+	s := Of(eventAt(0, 1), eventAt(40*time.Second, 2), eventAt(70*time.Second, 3))
+
+	// call
+	windows := SlidingWindow(s, eventTime, time.Minute, 30*time.Second).ToSlice()
+
+	// assert
+	require.Len(t, windows, 4, "wrong number of windows")
+	assert.Equal(t, TimeWindow[timedEvent]{windowOrigin.Add(-30 * time.Second), windowOrigin.Add(30 * time.Second), []timedEvent{eventAt(0, 1)}}, windows[0])
+	assert.Equal(t, TimeWindow[timedEvent]{windowOrigin, windowOrigin.Add(time.Minute), []timedEvent{eventAt(0, 1), eventAt(40*time.Second, 2)}}, windows[1])
+	assert.Equal(t, TimeWindow[timedEvent]{windowOrigin.Add(30 * time.Second), windowOrigin.Add(90 * time.Second),
+		[]timedEvent{eventAt(40*time.Second, 2), eventAt(70*time.Second, 3)}}, windows[2])
+	assert.Equal(t, TimeWindow[timedEvent]{windowOrigin.Add(time.Minute), windowOrigin.Add(2 * time.Minute), []timedEvent{eventAt(70*time.Second, 3)}}, windows[3])
+}
+
+func TestSessionWindow(t *testing.T) {
+	// prepare
+	s := Of(eventAt(0, 1), eventAt(20*time.Second, 2), eventAt(2*time.Minute, 5), eventAt(45*time.Second, 3))
+
+	// call
+	sums := Map(SessionWindow(s, eventTime, 30*time.Second), WindowReducer(func(sum int, e timedEvent) int { return sum + e.value }, 0))
+
+	// assert
+	assert.Equal(t, []WindowAggregate[int]{
+		{windowOrigin, windowOrigin.Add(75 * time.Second), 6},
+		{windowOrigin.Add(2 * time.Minute), windowOrigin.Add(150 * time.Second), 5},
+	}, sums.ToSlice(), "wrong per-session sums")
+}
+
+func TestWindowsOfEmptyStream(t *testing.T) {
+	// assert
+	assert.Empty(t, TumblingWindow(Of[timedEvent](), eventTime, time.Minute).ToSlice())
+	assert.Empty(t, SessionWindow(Of[timedEvent](), eventTime, time.Minute).ToSlice())
+	assert.PanicsWithValue(t, "strm: window size and slide must be positive", func() {
+		SlidingWindow(Of[timedEvent](), eventTime, time.Minute, 0)
+	})
+	assert.PanicsWithValue(t, "strm: session gap must be positive", func() {
+		SessionWindow(Of[timedEvent](), eventTime, 0)
+	})
+}
+
+func TestLazyWindowsFromChan(t *testing.T) {
+	// prepare
+	ch := make(chan timedEvent)
+	go func() {
+		defer close(ch)
+		for _, e := range []timedEvent{eventAt(0, 1), eventAt(30*time.Second, 2), eventAt(20*time.Second, 3), eventAt(65*time.Second, 4)} {
+			ch <- e
+		}
+	}()
+
+	// call
+	windows, err := LazyTumblingWindow(FromChan(ch), eventTime, time.Minute).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []TimeWindow[timedEvent]{
+		{windowOrigin, windowOrigin.Add(time.Minute), []timedEvent{eventAt(0, 1), eventAt(30*time.Second, 2), eventAt(20*time.Second, 3)}},
+		{windowOrigin.Add(time.Minute), windowOrigin.Add(2 * time.Minute), []timedEvent{eventAt(65*time.Second, 4)}},
+	}, windows, "wrong windows")
+}
+
+func TestLazyWindowsDropLateElements(t *testing.T) {
+	// prepare
+	events := []timedEvent{eventAt(0, 1), eventAt(65*time.Second, 2), eventAt(10*time.Second, 3), eventAt(70*time.Second, 4)}
+
+	// call
+	tumbling, err := LazyTumblingWindow(FromSeq(slices.Values(events)), eventTime, time.Minute).ToSlice()
+	sliding, err2 := LazySlidingWindow(FromSeq(slices.Values(events)), eventTime, time.Minute, 30*time.Second).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	require.NoError(t, err2)
+	assert.Equal(t, []TimeWindow[timedEvent]{
+		{windowOrigin, windowOrigin.Add(time.Minute), []timedEvent{eventAt(0, 1)}},
+		{windowOrigin.Add(time.Minute), windowOrigin.Add(2 * time.Minute), []timedEvent{eventAt(65*time.Second, 2), eventAt(70*time.Second, 4)}},
+	}, tumbling, "late elements shouldn't reopen emitted windows")
+	for _, window := range sliding {
+		assert.NotContains(t, window.Elements, eventAt(10*time.Second, 3), "late elements should be dropped")
+	}
+	assert.Len(t, sliding, 4, "wrong number of windows")
+}
+
+func TestLazySessionWindowDropsLateElements(t *testing.T) {
+	// prepare
+	events := []timedEvent{eventAt(0, 1), eventAt(100*time.Second, 2), eventAt(5*time.Second, 3),
+		eventAt(105*time.Second, 4), eventAt(95*time.Second, 5), eventAt(200*time.Second, 6)}
+
+	// call
+	sessions, err := LazySessionWindow(FromSeq(slices.Values(events)), eventTime, 10*time.Second).ToSlice()
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []TimeWindow[timedEvent]{
+		{windowOrigin, windowOrigin.Add(10 * time.Second), []timedEvent{eventAt(0, 1)}},
+		{windowOrigin.Add(100 * time.Second), windowOrigin.Add(115 * time.Second), []timedEvent{eventAt(100*time.Second, 2), eventAt(105*time.Second, 4)}},
+		{windowOrigin.Add(200 * time.Second), windowOrigin.Add(210 * time.Second), []timedEvent{eventAt(200*time.Second, 6)}},
+	}, sessions, "late elements should be dropped")
+}
+
+func TestLazyWindowsEmitOnClose(t *testing.T) {
+	// prepare
+	ch := make(chan timedEvent, 3)
+	ch <- eventAt(0, 1)
+	ch <- eventAt(10*time.Second, 2)
+	ch <- eventAt(time.Minute, 3)
+
+	// call
+	first, ok := LazySessionWindow(FromChan(ch), eventTime, 30*time.Second).First()
+	sliding, err := LazySlidingWindow(FromSeq(slices.Values([]timedEvent{eventAt(0, 1)})), eventTime, time.Minute, time.Minute).ToSlice()
+
+	// assert
+	assert.True(t, ok, "a session should be emitted before the channel is closed")
+	assert.Equal(t, TimeWindow[timedEvent]{windowOrigin, windowOrigin.Add(40 * time.Second), []timedEvent{eventAt(0, 1), eventAt(10*time.Second, 2)}}, first)
+	require.NoError(t, err)
+	assert.Len(t, sliding, 1, "wrong number of windows")
+}